@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"net/http"
+
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/logger"
+)
+
+// startAdmin serves log levels and circuit breakers on addr until ctx is
+// done. When token is set every request must carry it as a bearer token.
+func startAdmin(ctx context.Context, addr, token string, log logger.Logger) error {
+	mux := http.NewServeMux()
+	mux.Handle("/admin/log/level", logger.DefaultLevels.Handler())
+	mux.Handle("/admin/breakers", errors.DefaultBreakers.Handler())
+
+	var handler http.Handler = mux
+	if token != "" {
+		handler = requireToken(token, mux)
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	if !config.IsLoopbackAddr(addr) {
+		log.Warn("admin endpoint is reachable from other hosts over plain HTTP",
+			logger.FieldString("address", addr))
+	}
+
+	srv := &http.Server{Handler: errors.PanicRecovery(handler)}
+	go func() {
+		if err := srv.Serve(lis); err != nil && err != http.ErrServerClosed {
+			log.Error("admin server failed", logger.FieldError(err))
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Info("admin endpoint started", logger.FieldString("address", lis.Addr().String()))
+	return nil
+}
+
+// requireToken rejects requests without "Authorization: Bearer <token>"
+func requireToken(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			errors.WriteHTTPErrorForRequest(w, r,
+				errors.New(errors.CodeUnauthorized, "admin token required"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// watchLogLevel applies logging.level changes in the config file to every
+// registered logger without a restart
+func watchLogLevel(ctx context.Context, loader *config.Loader, current string, log logger.Logger) {
+	updates := loader.Watch()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			if update.Error != nil {
+				log.Warn("config reload failed", logger.FieldError(update.Error))
+				continue
+			}
+			if update.Config.Logging.Level == current {
+				continue
+			}
+
+			level, err := logger.ParseLevel(update.Config.Logging.Level)
+			if err != nil {
+				log.Warn("ignoring log level change", logger.FieldError(err))
+				continue
+			}
+			// logged first so the change is visible when raising the level
+			log.Info("changing log level", logger.FieldString("level", level.String()))
+			logger.DefaultLevels.SetAll(level)
+			current = update.Config.Logging.Level
+		}
+	}
+}
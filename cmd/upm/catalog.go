@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"upm-simple/pkg/errors"
+)
+
+func errorsCatalog(args []string) error {
+	fs := flag.NewFlagSet("errors catalog", flag.ExitOnError)
+	format := fs.String("format", "markdown", "output format: markdown or json")
+	fs.Parse(args)
+
+	switch *format {
+	case "markdown", "md":
+		return errors.WriteCatalogMarkdown(os.Stdout)
+	case "json":
+		return errors.WriteCatalogJSON(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
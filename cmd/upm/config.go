@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"upm-simple/pkg/logger"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func configValidate(args []string) error {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	fs.Parse(args)
+
+	loader, _, err := cf.newLoader()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("OK: %s\n", loader.GetViper().ConfigFileUsed())
+	return nil
+}
+
+// configPrint prints the merged settings from file, environment and defaults
+// with secrets such as admin_token masked
+func configPrint(args []string) error {
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	fs.Parse(args)
+
+	loader, cfg, err := cf.newLoader()
+	if err != nil {
+		return err
+	}
+
+	return printSettings(os.Stdout, loader.GetViper().AllSettings(), cfg.Logging.Redaction)
+}
+
+// printSettings writes settings as YAML after masking the keys and values
+// the log redaction would hide, whether or not log redaction is enabled
+func printSettings(w io.Writer, settings map[string]interface{}, redaction logger.RedactionConfig) error {
+	redactor, err := logger.NewRedactor(redaction)
+	if err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(redactor.Map(settings)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
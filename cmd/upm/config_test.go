@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"upm-simple/pkg/logger"
+)
+
+func TestPrintSettingsMasksSecrets(t *testing.T) {
+	settings := map[string]interface{}{
+		"server": map[string]interface{}{
+			"port":        50051,
+			"admin_addr":  "0.0.0.0:9090",
+			"admin_token": "admin-s3cret",
+		},
+		"nats": map[string]interface{}{
+			"url":      "nats://localhost:4222",
+			"password": "nats-s3cret",
+		},
+		"webhook": map[string]interface{}{
+			"signing": "signing-s3cret",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := printSettings(&buf, settings, logger.RedactionConfig{Keys: []string{"signing"}}); err != nil {
+		t.Fatalf("printSettings: %v", err)
+	}
+	out := buf.String()
+
+	if strings.Contains(out, "s3cret") {
+		t.Errorf("secret printed:\n%s", out)
+	}
+	for _, want := range []string{"admin_token: '[REDACTED]'", "port: 50051", "url: nats://localhost:4222", "admin_addr: 0.0.0.0:9090"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no %q:\n%s", want, out)
+		}
+	}
+
+	// the settings themselves are left alone
+	if settings["server"].(map[string]interface{})["admin_token"] != "admin-s3cret" {
+		t.Error("printSettings modified the settings")
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/tlsutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const usage = `upm - Universal Protocol Mocking platform CLI
+
+Usage:
+  upm registry serve                     run the service registry
+  upm registry register [flags]          register a service (optionally keep it alive)
+  upm registry deregister --id ID        remove a registered service
+  upm registry list [flags]              discover registered services
+  upm registry watch [--name NAME]       stream registry changes
+  upm nats pub --subject S --data D      publish a message
+  upm nats sub --subject S               print messages until interrupted
+  upm config validate                    load and validate the configuration
+  upm config print                       print the effective configuration
+  upm errors catalog [--format json]     list error codes with their HTTP/gRPC mappings
+
+Registry, nats and config commands accept --env (dev, test, prod) and --config PATH.
+Run "upm <group> <command> --help" for command flags.
+`
+
+type command func(args []string) error
+
+var commands = map[string]map[string]command{
+	"registry": {
+		"serve":      registryServe,
+		"register":   registryRegister,
+		"deregister": registryDeregister,
+		"list":       registryList,
+		"watch":      registryWatch,
+	},
+	"nats": {
+		"pub": natsPub,
+		"sub": natsSub,
+	},
+	"config": {
+		"validate": configValidate,
+		"print":    configPrint,
+	},
+	"errors": {
+		"catalog": errorsCatalog,
+	},
+}
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	group, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command group %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	cmd, ok := group[os.Args[2]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q for %s\n\n%s", os.Args[2], os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err := cmd(os.Args[3:]); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// configFlags are the --env/--config flags shared by every command
+type configFlags struct {
+	env  *string
+	path *string
+}
+
+func addConfigFlags(fs *flag.FlagSet) *configFlags {
+	return &configFlags{
+		env:  fs.String("env", os.Getenv("UPM_ENVIRONMENT"), "environment to load config for: dev, test or prod"),
+		path: fs.String("config", "", "path to a config file (overrides --env)"),
+	}
+}
+
+// newLoader returns a loader pointed at the selected config file, or one that
+// searches the default locations when neither flag is set. An --env without a
+// config file is an error rather than a silent fallback to the defaults.
+func (f *configFlags) newLoader() (*config.Loader, *config.Config, error) {
+	loader := config.NewLoader()
+
+	path := *f.path
+	if path == "" && *f.env != "" {
+		file, err := config.GetConfigFile(*f.env)
+		if err != nil {
+			return nil, nil, fmt.Errorf("no config for environment %q: %w", *f.env, err)
+		}
+		path = file
+	}
+
+	var cfg *config.Config
+	var err error
+	if path != "" {
+		cfg, err = loader.LoadFromFile(path)
+	} else {
+		cfg, err = loader.Load()
+	}
+	return loader, cfg, err
+}
+
+func (f *configFlags) load() (*config.Config, error) {
+	_, cfg, err := f.newLoader()
+	return cfg, err
+}
+
+// clientFlags select and secure the registry connection for client commands
+type clientFlags struct {
+	*configFlags
+	addr       *string
+	useTLS     *bool
+	caPath     *string
+	certPath   *string
+	keyPath    *string
+	serverName *string
+	timeout    *time.Duration
+}
+
+func addClientFlags(fs *flag.FlagSet) *clientFlags {
+	return &clientFlags{
+		configFlags: addConfigFlags(fs),
+		addr:        fs.String("addr", "", "registry address (default: from server.host/port in config)"),
+		useTLS:      fs.Bool("tls", false, "connect with TLS (default: server.enable_tls in config)"),
+		caPath:      fs.String("tls-ca", "", "CA bundle to verify the registry (default: system roots)"),
+		certPath:    fs.String("tls-cert", "", "client certificate for mutual TLS"),
+		keyPath:     fs.String("tls-key", "", "client key for mutual TLS"),
+		serverName:  fs.String("tls-server-name", "", "override the server name checked in its certificate"),
+		timeout:     fs.Duration("timeout", 5*time.Second, "connect and request timeout"),
+	}
+}
+
+// dial connects to the registry using flags first and the config file second
+func (f *clientFlags) dial() (pb.ServiceRegistryClient, *grpc.ClientConn, error) {
+	cfg, err := f.load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addr := *f.addr
+	if addr == "" {
+		host := cfg.Server.Host
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+		addr = net.JoinHostPort(host, strconv.Itoa(cfg.Server.Port))
+	}
+
+	creds := insecure.NewCredentials()
+	if *f.useTLS || cfg.Server.EnableTLS {
+		creds, err = tlsutil.ClientCredentials(tlsutil.ClientOptions{
+			CAPath:     *f.caPath,
+			CertPath:   *f.certPath,
+			KeyPath:    *f.keyPath,
+			ServerName: *f.serverName,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *f.timeout)
+	defer cancel()
+
+	resilience := errors.DefaultResilienceConfig()
+	resilience.DefaultTimeout = *f.timeout
+	// Register and Deregister change state and must not be sent twice
+	resilience.IdempotentMethods = []string{
+		"/registry.ServiceRegistry/Discover",
+		"/registry.ServiceRegistry/Heartbeat",
+	}
+
+	conn, err := grpc.DialContext(ctx, addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(errors.GRPCClientInterceptor(resilience)),
+		grpc.WithBlock())
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot connect to registry at %s: %w", addr, err)
+	}
+
+	return pb.NewServiceRegistryClient(conn), conn, nil
+}
+
+func (f *clientFlags) requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), *f.timeout)
+}
+
+// signalContext is cancelled on SIGINT or SIGTERM
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
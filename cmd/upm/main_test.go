@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigFlagsEnv(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := addConfigFlags(fs)
+	if err := fs.Parse([]string{"--env", "dev"}); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+
+	// no config directory anywhere, so --env dev must not fall back to defaults
+	if _, err := flags.load(); err == nil || !strings.Contains(err.Error(), `"dev"`) {
+		t.Fatalf("load err = %v, want an error naming the environment", err)
+	}
+
+	dir := filepath.Join(home, ".upm", "configs", "dev")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("server:\n  port: 7777\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := flags.load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Server.Port != 7777 {
+		t.Errorf("port = %d, want 7777 from the dev config", cfg.Server.Port)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"upm-simple/pkg/config"
+
+	"github.com/nats-io/nats.go"
+)
+
+func natsPub(args []string) error {
+	fs := flag.NewFlagSet("nats pub", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	subject := fs.String("subject", "", "subject to publish to (required)")
+	data := fs.String("data", "", "message payload")
+	fs.Parse(args)
+
+	if *subject == "" {
+		return fmt.Errorf("--subject is required")
+	}
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+
+	nc, err := natsConnect(cfg.NATS)
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+
+	if err := nc.Publish(*subject, []byte(*data)); err != nil {
+		return err
+	}
+	return nc.FlushTimeout(cfg.NATS.Timeout)
+}
+
+func natsSub(args []string) error {
+	fs := flag.NewFlagSet("nats sub", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	subject := fs.String("subject", "", "subject to subscribe to, wildcards allowed (required)")
+	fs.Parse(args)
+
+	if *subject == "" {
+		return fmt.Errorf("--subject is required")
+	}
+
+	cfg, err := cf.load()
+	if err != nil {
+		return err
+	}
+
+	nc, err := natsConnect(cfg.NATS)
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+
+	sub, err := nc.Subscribe(*subject, func(m *nats.Msg) {
+		fmt.Printf("[%s] %s\n", m.Subject, string(m.Data))
+	})
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	ctx, stop := signalContext()
+	defer stop()
+	<-ctx.Done()
+	return nil
+}
+
+func natsConnect(cfg config.NATSConfig) (*nats.Conn, error) {
+	opts := []nats.Option{
+		nats.MaxReconnects(cfg.MaxReconnects),
+		nats.ReconnectWait(cfg.ReconnectWait),
+	}
+	if cfg.Timeout > 0 {
+		opts = append(opts, nats.Timeout(cfg.Timeout))
+	}
+	if cfg.ClientID != "" {
+		opts = append(opts, nats.Name(cfg.ClientID))
+	}
+
+	nc, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to NATS at %s: %w", cfg.URL, err)
+	}
+	return nc, nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "upm-simple/internal"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var protocols = map[string]pb.Protocol{
+	"http": pb.Protocol_PROTOCOL_HTTP,
+	"grpc": pb.Protocol_PROTOCOL_GRPC,
+	"mqtt": pb.Protocol_PROTOCOL_MQTT,
+	"tcp":  pb.Protocol_PROTOCOL_TCP,
+}
+
+var healthStatuses = map[string]pb.HealthStatus{
+	"unknown":   pb.HealthStatus_HEALTH_STATUS_UNKNOWN,
+	"healthy":   pb.HealthStatus_HEALTH_STATUS_HEALTHY,
+	"unhealthy": pb.HealthStatus_HEALTH_STATUS_UNHEALTHY,
+	"draining":  pb.HealthStatus_HEALTH_STATUS_DRAINING,
+}
+
+func registryRegister(args []string) error {
+	fs := flag.NewFlagSet("registry register", flag.ExitOnError)
+	cf := addClientFlags(fs)
+	name := fs.String("name", "", "service name (required)")
+	host := fs.String("host", "localhost", "service host")
+	port := fs.Int("port", 0, "service port (required)")
+	version := fs.String("version", "", "service version, e.g. 1.2.0")
+	protocol := fs.String("protocol", "", "service protocol: http, grpc, mqtt or tcp")
+	weight := fs.Int("weight", 0, "weight for weighted load balancing")
+	zone := fs.String("zone", "", "availability zone")
+	keepalive := fs.Bool("keepalive", false, "keep sending heartbeats until interrupted, then deregister")
+	var tags, meta stringList
+	fs.Var(&tags, "tag", "service tag (repeatable)")
+	fs.Var(&meta, "meta", "metadata as key=value (repeatable)")
+	fs.Parse(args)
+
+	if *name == "" || *port <= 0 {
+		return fmt.Errorf("--name and --port are required")
+	}
+
+	svc := &pb.Service{
+		Name:    *name,
+		Host:    *host,
+		Port:    int32(*port),
+		Version: *version,
+		Tags:    tags,
+		Weight:  int32(*weight),
+		Zone:    *zone,
+		Health:  pb.HealthStatus_HEALTH_STATUS_HEALTHY,
+	}
+
+	if *protocol != "" {
+		p, ok := protocols[strings.ToLower(*protocol)]
+		if !ok {
+			return fmt.Errorf("unknown protocol %q", *protocol)
+		}
+		svc.Protocol = p
+	}
+
+	if len(meta) > 0 {
+		svc.Metadata = make(map[string]string, len(meta))
+		for _, kv := range meta {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return fmt.Errorf("invalid metadata %q, expected key=value", kv)
+			}
+			svc.Metadata[k] = v
+		}
+	}
+
+	client, conn, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := cf.requestContext()
+	resp, err := client.Register(ctx, &pb.RegisterRequest{Service: svc})
+	cancel()
+	if err != nil {
+		return err
+	}
+	fmt.Println(resp.ServiceId)
+
+	if !*keepalive {
+		return nil
+	}
+	return keepRegistered(client, svc, resp.ServiceId, *cf.timeout)
+}
+
+// keepRegistered heartbeats at the interval the registry asks for, registers
+// again if the registry has forgotten the service, and deregisters on exit
+func keepRegistered(client pb.ServiceRegistryClient, svc *pb.Service, id string, timeout time.Duration) error {
+	ctx, stop := signalContext()
+	defer stop()
+
+	interval := time.Second
+	for {
+		select {
+		case <-ctx.Done():
+			dctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			_, err := client.Deregister(dctx, &pb.DeregisterRequest{ServiceId: id})
+			return err
+		case <-time.After(interval):
+		}
+
+		hctx, cancel := context.WithTimeout(ctx, timeout)
+		resp, err := client.Heartbeat(hctx, &pb.HeartbeatRequest{ServiceId: id})
+		cancel()
+
+		switch {
+		case err == nil:
+			if resp.HeartbeatIntervalMs > 0 {
+				interval = time.Duration(resp.HeartbeatIntervalMs) * time.Millisecond
+			}
+		case status.Code(err) == codes.NotFound:
+			rctx, cancel := context.WithTimeout(ctx, timeout)
+			rresp, rerr := client.Register(rctx, &pb.RegisterRequest{Service: svc})
+			cancel()
+			if rerr != nil {
+				fmt.Fprintf(os.Stderr, "re-register failed: %v\n", rerr)
+				continue
+			}
+			id = rresp.ServiceId
+			fmt.Fprintf(os.Stderr, "re-registered as %s\n", id)
+		case ctx.Err() == nil:
+			fmt.Fprintf(os.Stderr, "heartbeat failed: %v\n", err)
+		}
+	}
+}
+
+func registryDeregister(args []string) error {
+	fs := flag.NewFlagSet("registry deregister", flag.ExitOnError)
+	cf := addClientFlags(fs)
+	id := fs.String("id", "", "service ID (required)")
+	fs.Parse(args)
+
+	if *id == "" {
+		return fmt.Errorf("--id is required")
+	}
+
+	client, conn, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := cf.requestContext()
+	defer cancel()
+
+	_, err = client.Deregister(ctx, &pb.DeregisterRequest{ServiceId: *id})
+	return err
+}
+
+func registryList(args []string) error {
+	fs := flag.NewFlagSet("registry list", flag.ExitOnError)
+	cf := addClientFlags(fs)
+	name := fs.String("name", "", "only services with this name")
+	version := fs.String("version", "", "version constraint, e.g. \">=1.0 <2\" or ^1.2")
+	pick := fs.Bool("pick", false, "let the registry pick a single instance (requires --name)")
+	hashKey := fs.String("hash-key", "", "key for consistent-hash picking")
+	var tags, health stringList
+	fs.Var(&tags, "tag", "only services with this tag (repeatable)")
+	fs.Var(&health, "health", "only services in this health state (repeatable)")
+	fs.Parse(args)
+
+	req := &pb.DiscoverRequest{
+		ServiceName:       *name,
+		PickOne:           *pick,
+		HashKey:           *hashKey,
+		Tags:              tags,
+		VersionConstraint: *version,
+	}
+	for _, h := range health {
+		s, ok := healthStatuses[strings.ToLower(h)]
+		if !ok {
+			return fmt.Errorf("unknown health status %q", h)
+		}
+		req.Health = append(req.Health, s)
+	}
+
+	client, conn, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := cf.requestContext()
+	defer cancel()
+
+	resp, err := client.Discover(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tADDRESS\tVERSION\tPROTOCOL\tHEALTH\tTAGS")
+	for _, svc := range resp.Services {
+		fmt.Fprintf(w, "%s\t%s\t%s:%d\t%s\t%s\t%s\t%s\n",
+			svc.Id, svc.Name, svc.Host, svc.Port, svc.Version,
+			protocolName(svc.Protocol), healthName(svc.Health), strings.Join(svc.Tags, ","))
+	}
+	return w.Flush()
+}
+
+func registryWatch(args []string) error {
+	fs := flag.NewFlagSet("registry watch", flag.ExitOnError)
+	cf := addClientFlags(fs)
+	name := fs.String("name", "", "only events for services with this name")
+	fs.Parse(args)
+
+	client, conn, err := cf.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, stop := signalContext()
+	defer stop()
+
+	stream, err := client.Watch(ctx, &pb.WatchRequest{ServiceName: *name})
+	if err != nil {
+		return err
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if event.Type == pb.WatchEvent_SYNCED {
+			fmt.Println("--- synced ---")
+			continue
+		}
+		svc := event.Service
+		fmt.Printf("%-8s %s %s %s:%d %s\n", event.Type, svc.Id, svc.Name,
+			svc.Host, svc.Port, healthName(svc.Health))
+	}
+}
+
+func protocolName(p pb.Protocol) string {
+	return strings.ToLower(strings.TrimPrefix(p.String(), "PROTOCOL_"))
+}
+
+func healthName(h pb.HealthStatus) string {
+	return strings.ToLower(strings.TrimPrefix(h.String(), "HEALTH_STATUS_"))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/logger"
+	"upm-simple/pkg/registry"
+	"upm-simple/pkg/tlsutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// how long GracefulStop may drain in-flight RPCs before a hard Stop
+const shutdownTimeout = 10 * time.Second
+
+func registryServe(args []string) error {
+	fs := flag.NewFlagSet("registry serve", flag.ExitOnError)
+	cf := addConfigFlags(fs)
+	fs.Parse(args)
+
+	loader, cfg, err := cf.newLoader()
+	if err != nil {
+		return err
+	}
+	errors.SetStackTraceConfig(errors.StackTraceConfigFor(cfg.Environment))
+
+	log, err := logger.FromAppConfig(cfg.Logging.AppConfig())
+	if err != nil {
+		return err
+	}
+	defer log.Sync()
+	logger.SetDefault(log)
+	logger.DefaultLevels.Register("root", log)
+	slog.SetDefault(slog.New(logger.NewSlogHandler(log)))
+
+	addr := net.JoinHostPort(cfg.Server.Host, strconv.Itoa(cfg.Server.Port))
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	store, err := registry.NewStore(cfg.Registry.Store)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	srv, err := registry.NewServer(cfg.Registry, store, logger.Named(log, "registry"))
+	if err != nil {
+		return err
+	}
+
+	opts, err := serverOptions(cfg.Server)
+	if err != nil {
+		return err
+	}
+
+	s := grpc.NewServer(opts...)
+	pb.RegisterServiceRegistryServer(s, srv)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	go srv.RunReaper(ctx)
+	go watchLogLevel(ctx, loader, cfg.Logging.Level, log)
+
+	if cfg.Server.AdminAddr != "" {
+		if err := startAdmin(ctx, cfg.Server.AdminAddr, cfg.Server.AdminToken, log); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		log.Info("shutting down")
+		// watch streams never end on their own, so close them first
+		srv.Shutdown()
+		gracefulStop(s, shutdownTimeout, log)
+	}()
+
+	log.Info("service registry started",
+		logger.FieldString("address", addr),
+		logger.FieldString("environment", cfg.Environment),
+		logger.FieldBool("tls", cfg.Server.EnableTLS),
+		logger.FieldBool("mtls", cfg.Server.EnableTLS && cfg.Server.RequireClientCert),
+		logger.FieldString("store", cfg.Registry.Store.Type),
+		logger.FieldString("load_balancing", srv.Balancer().Name()),
+	)
+
+	if err := s.Serve(lis); err != nil {
+		return err
+	}
+	log.Info("service registry stopped")
+	return nil
+}
+
+func serverOptions(cfg config.ServerConfig) ([]grpc.ServerOption, error) {
+	opts := errors.ChainServerOptions()
+
+	if cfg.EnableTLS {
+		creds, err := tlsutil.ServerCredentials(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	if cfg.IdleTimeout > 0 {
+		opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle: cfg.IdleTimeout,
+		}))
+	}
+
+	return opts, nil
+}
+
+// gracefulStop drains in-flight RPCs and falls back to a hard stop after timeout
+func gracefulStop(s *grpc.Server, timeout time.Duration, log logger.Logger) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Warn("graceful shutdown timed out, forcing stop")
+		s.Stop()
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/logger"
+	"upm-simple/pkg/registry"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// startRegistry serves a registry on a loopback port and returns a client
+func startRegistry(t *testing.T) (*grpc.Server, *registry.Server, pb.ServiceRegistryClient) {
+	t.Helper()
+
+	srv, err := registry.NewServer(config.RegistryConfig{}, registry.NewMemoryStore(), &logger.NoopLogger{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	s := grpc.NewServer()
+	pb.RegisterServiceRegistryServer(s, srv)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return s, srv, pb.NewServiceRegistryClient(conn)
+}
+
+// openWatch starts a Watch stream and waits for its snapshot to end
+func openWatch(t *testing.T, client pb.ServiceRegistryClient) pb.ServiceRegistry_WatchClient {
+	t.Helper()
+
+	stream, err := client.Watch(context.Background(), &pb.WatchRequest{})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if event, err := stream.Recv(); err != nil || event.Type != pb.WatchEvent_SYNCED {
+		t.Fatalf("first event = %v, %v; want SYNCED", event, err)
+	}
+	return stream
+}
+
+func TestGracefulStopWithoutRPCs(t *testing.T) {
+	s, _, client := startRegistry(t)
+	if _, err := client.Discover(context.Background(), &pb.DiscoverRequest{}); err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+
+	start := time.Now()
+	gracefulStop(s, 5*time.Second, &logger.NoopLogger{})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("idle server took %v to stop", elapsed)
+	}
+}
+
+func TestGracefulStopForcesAfterTimeout(t *testing.T) {
+	s, _, client := startRegistry(t)
+	openWatch(t, client)
+
+	start := time.Now()
+	gracefulStop(s, 100*time.Millisecond, &logger.NoopLogger{})
+	elapsed := time.Since(start)
+	if elapsed < 100*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("stop with an open stream took %v, want the 100ms timeout", elapsed)
+	}
+}
+
+func TestShutdownLetsGracefulStopFinish(t *testing.T) {
+	s, srv, client := startRegistry(t)
+	stream := openWatch(t, client)
+
+	start := time.Now()
+	srv.Shutdown()
+	gracefulStop(s, 5*time.Second, &logger.NoopLogger{})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("stop with an open watch took %v after Shutdown", elapsed)
+	}
+
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("watcher got %v, want Unavailable", err)
+	}
+}
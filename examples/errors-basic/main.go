@@ -43,13 +43,11 @@ func main() {
 
 	// retry mechanism
 	fmt.Println("\n5. Retry Mechanism:")
-	attempt := 0
-	retryFunc := func() error {
-		attempt++
+	retryFunc := func(ctx context.Context, attempt int) error {
 		fmt.Printf("  Attempt %d... ", attempt)
 		if attempt < 3 {
 			fmt.Println("Failed")
-			return fmt.Errorf("temporary failure")
+			return errors.New(errors.CodeNetworkError, "temporary failure")
 		}
 		fmt.Println("Success!")
 		return nil
@@ -57,11 +55,17 @@ func main() {
 
 	retryConfig := errors.DefaultRetryConfig()
 	retryConfig.MaxAttempts = 5
+	retryConfig.AttemptTimeout = time.Second
+	retryConfig.JitterStrategy = errors.JitterEqual
+	retryConfig.Budget = errors.NewRetryBudget(10, 1)
+	retryConfig.OnRetry = func(attempt int, err error, delay time.Duration) {
+		fmt.Printf("  retrying in %v\n", delay.Round(time.Millisecond))
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err := errors.Retry(ctx, retryConfig, retryFunc)
+	err := errors.RetryWithContext(ctx, retryConfig, retryFunc)
 	if err != nil {
 		fmt.Printf("Retry failed: %v\n", err)
 	} else {
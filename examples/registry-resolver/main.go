@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/registry"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Run the registry first on the default port:
+//
+//	UPM_SERVER_PORT=50051 go run ./cmd/upm registry serve
+func main() {
+	fmt.Println("=== Registry Resolver Example ===")
+
+	conn, err := grpc.Dial("localhost:50051",
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal("Cannot connect to registry:", err)
+	}
+	defer conn.Close()
+
+	registryClient := pb.NewServiceRegistryClient(conn)
+
+	// register the registry itself under a logical name so there is
+	// something reachable to resolve
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	fmt.Println("\n1. Registering 'registry-self' -> localhost:50051")
+	resp, err := registryClient.Register(ctx, &pb.RegisterRequest{
+		Service: &pb.Service{Name: "registry-self", Host: "localhost", Port: 50051},
+	})
+	if err != nil {
+		log.Fatal("Register failed:", err)
+	}
+	defer registryClient.Deregister(context.Background(), &pb.DeregisterRequest{ServiceId: resp.ServiceId})
+
+	// dial by logical name; addresses come from the registry
+	fmt.Println("\n2. Dialing upm:///registry-self")
+	opts := append(registry.DialOptions(registryClient),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	target, err := grpc.Dial("upm:///registry-self", opts...)
+	if err != nil {
+		log.Fatal("Dial failed:", err)
+	}
+	defer target.Close()
+
+	// wait for the resolver so the call does not fail fast
+	discResp, err := pb.NewServiceRegistryClient(target).Discover(ctx,
+		&pb.DiscoverRequest{ServiceName: "registry-self"}, grpc.WaitForReady(true))
+	if err != nil {
+		log.Fatal("Call through resolved connection failed:", err)
+	}
+	fmt.Printf("   Call succeeded, registry reports %d instance(s)\n", len(discResp.Services))
+
+	fmt.Println("\n Resolver example completed!")
+}
@@ -23,7 +23,10 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ServiceRegistryClient interface {
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	Deregister(ctx context.Context, in *DeregisterRequest, opts ...grpc.CallOption) (*DeregisterResponse, error)
 	Discover(ctx context.Context, in *DiscoverRequest, opts ...grpc.CallOption) (*DiscoverResponse, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (ServiceRegistry_WatchClient, error)
 }
 
 type serviceRegistryClient struct {
@@ -43,6 +46,15 @@ func (c *serviceRegistryClient) Register(ctx context.Context, in *RegisterReques
 	return out, nil
 }
 
+func (c *serviceRegistryClient) Deregister(ctx context.Context, in *DeregisterRequest, opts ...grpc.CallOption) (*DeregisterResponse, error) {
+	out := new(DeregisterResponse)
+	err := c.cc.Invoke(ctx, "/registry.ServiceRegistry/Deregister", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *serviceRegistryClient) Discover(ctx context.Context, in *DiscoverRequest, opts ...grpc.CallOption) (*DiscoverResponse, error) {
 	out := new(DiscoverResponse)
 	err := c.cc.Invoke(ctx, "/registry.ServiceRegistry/Discover", in, out, opts...)
@@ -52,12 +64,56 @@ func (c *serviceRegistryClient) Discover(ctx context.Context, in *DiscoverReques
 	return out, nil
 }
 
+func (c *serviceRegistryClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, "/registry.ServiceRegistry/Heartbeat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceRegistryClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (ServiceRegistry_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceRegistry_ServiceDesc.Streams[0], "/registry.ServiceRegistry/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceRegistryWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ServiceRegistry_WatchClient interface {
+	Recv() (*WatchEvent, error)
+	grpc.ClientStream
+}
+
+type serviceRegistryWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceRegistryWatchClient) Recv() (*WatchEvent, error) {
+	m := new(WatchEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ServiceRegistryServer is the server API for ServiceRegistry service.
 // All implementations must embed UnimplementedServiceRegistryServer
 // for forward compatibility
 type ServiceRegistryServer interface {
 	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	Deregister(context.Context, *DeregisterRequest) (*DeregisterResponse, error)
 	Discover(context.Context, *DiscoverRequest) (*DiscoverResponse, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	Watch(*WatchRequest, ServiceRegistry_WatchServer) error
 	mustEmbedUnimplementedServiceRegistryServer()
 }
 
@@ -68,9 +124,18 @@ type UnimplementedServiceRegistryServer struct {
 func (UnimplementedServiceRegistryServer) Register(context.Context, *RegisterRequest) (*RegisterResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
 }
+func (UnimplementedServiceRegistryServer) Deregister(context.Context, *DeregisterRequest) (*DeregisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deregister not implemented")
+}
 func (UnimplementedServiceRegistryServer) Discover(context.Context, *DiscoverRequest) (*DiscoverResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Discover not implemented")
 }
+func (UnimplementedServiceRegistryServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedServiceRegistryServer) Watch(*WatchRequest, ServiceRegistry_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
 func (UnimplementedServiceRegistryServer) mustEmbedUnimplementedServiceRegistryServer() {}
 
 // UnsafeServiceRegistryServer may be embedded to opt out of forward compatibility for this service.
@@ -102,6 +167,24 @@ func _ServiceRegistry_Register_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ServiceRegistry_Deregister_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeregisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceRegistryServer).Deregister(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/registry.ServiceRegistry/Deregister",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceRegistryServer).Deregister(ctx, req.(*DeregisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ServiceRegistry_Discover_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DiscoverRequest)
 	if err := dec(in); err != nil {
@@ -120,6 +203,45 @@ func _ServiceRegistry_Discover_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ServiceRegistry_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceRegistryServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/registry.ServiceRegistry/Heartbeat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceRegistryServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ServiceRegistry_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceRegistryServer).Watch(m, &serviceRegistryWatchServer{stream})
+}
+
+type ServiceRegistry_WatchServer interface {
+	Send(*WatchEvent) error
+	grpc.ServerStream
+}
+
+type serviceRegistryWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceRegistryWatchServer) Send(m *WatchEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // ServiceRegistry_ServiceDesc is the grpc.ServiceDesc for ServiceRegistry service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -131,11 +253,25 @@ var ServiceRegistry_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Register",
 			Handler:    _ServiceRegistry_Register_Handler,
 		},
+		{
+			MethodName: "Deregister",
+			Handler:    _ServiceRegistry_Deregister_Handler,
+		},
 		{
 			MethodName: "Discover",
 			Handler:    _ServiceRegistry_Discover_Handler,
 		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _ServiceRegistry_Heartbeat_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _ServiceRegistry_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "service.proto",
 }
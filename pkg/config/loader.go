@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
@@ -29,6 +30,13 @@ func NewLoader() *Loader {
 	v.SetDefault("server.read_timeout", "30s")
 	v.SetDefault("server.write_timeout", "30s")
 	v.SetDefault("server.idle_timeout", "60s")
+	v.SetDefault("server.enable_tls", false)
+	v.SetDefault("server.tls_cert_path", "")
+	v.SetDefault("server.tls_key_path", "")
+	v.SetDefault("server.tls_ca_path", "")
+	v.SetDefault("server.require_client_cert", false)
+	v.SetDefault("server.admin_addr", "")
+	v.SetDefault("server.admin_token", "")
 	v.SetDefault("nats.url", "nats://localhost:4222")
 	v.SetDefault("nats.cluster_id", "test-cluster")
 	v.SetDefault("nats.max_reconnects", -1)
@@ -38,10 +46,15 @@ func NewLoader() *Loader {
 	v.SetDefault("logging.format", "json")
 	v.SetDefault("logging.output", "stdout")
 	v.SetDefault("logging.enable_json", true)
+	v.SetDefault("logging.redaction.enabled", true)
 	v.SetDefault("registry.heartbeat_interval", "30s")
 	v.SetDefault("registry.heartbeat_timeout", "90s")
 	v.SetDefault("registry.load_balancing_strategy", "round_robin")
 	v.SetDefault("registry.cache_ttl", "5m")
+	v.SetDefault("registry.store.type", "memory")
+	v.SetDefault("registry.store.path", "data/registry")
+	v.SetDefault("registry.store.snapshot_threshold", 1000)
+	v.SetDefault("registry.store.sync_writes", true)
 	v.SetDefault("features.enable_metrics", true)
 
 	v.SetConfigName("config")
@@ -61,30 +74,41 @@ func NewLoader() *Loader {
 
 // load configuration from file and environment
 func (l *Loader) Load() (*Config, error) {
-	configFound := false
+	if l.path != "" {
+		// an explicit file (LoadFromFile or a previous Load) must exist
+		l.viper.SetConfigFile(l.path)
+		if err := l.viper.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read config %s: %w", l.path, err)
+		}
+	} else {
+		configFound := false
 
-	configPaths := []string{
-		"configs/dev/config.yaml",
-		"configs/config.yaml",
-		"./config.yaml",
-	}
+		configPaths := []string{
+			"configs/dev/config.yaml",
+			"configs/config.yaml",
+			"./config.yaml",
+		}
 
-	for _, path := range configPaths {
-		l.viper.SetConfigFile(path)
-		if err := l.viper.ReadInConfig(); err == nil {
-			l.path = path
-			configFound = true
-			fmt.Printf("Config loaded from: %s\n", path)
-			break
+		for _, path := range configPaths {
+			l.viper.SetConfigFile(path)
+			if err := l.viper.ReadInConfig(); err == nil {
+				l.path = path
+				configFound = true
+				fmt.Printf("Config loaded from: %s\n", path)
+				break
+			}
 		}
-	}
 
-	if !configFound {
-		fmt.Println("No config file found, using defaults and environment variables")
+		if !configFound {
+			fmt.Println("No config file found, using defaults and environment variables")
+		}
 	}
 
+	// keys use the yaml names (read_timeout), not the Go field names
 	var config Config
-	if err := l.viper.Unmarshal(&config); err != nil {
+	if err := l.viper.Unmarshal(&config, func(dc *mapstructure.DecoderConfig) {
+		dc.TagName = "yaml"
+	}); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
@@ -151,8 +175,9 @@ func (l *Loader) watchConfig(updates chan<- ConfigUpdate) {
 				return
 			}
 
-			// Check if config file was modified
-			if event.Op&fsnotify.Write == fsnotify.Write &&
+			// Check if config file was modified; editors that save by
+			// renaming a new file into place produce a Create instead
+			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 &&
 				filepath.Base(event.Name) == filepath.Base(l.path) {
 				time.Sleep(100 * time.Millisecond) // Wait for write to complete
 
@@ -2,7 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"time"
+
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/logger"
 )
 
 // server configuration
@@ -19,6 +23,18 @@ type ServerConfig struct {
 	EnableTLS   bool   `yaml:"enable_tls" env:"ENABLE_TLS" default:"false"`
 	TLSCertPath string `yaml:"tls_cert_path" env:"TLS_CERT_PATH"`
 	TLSKeyPath  string `yaml:"tls_key_path" env:"TLS_KEY_PATH"`
+
+	// mutual TLS: CA bundle for client certificates, and whether one is mandatory
+	TLSCAPath         string `yaml:"tls_ca_path" env:"TLS_CA_PATH"`
+	RequireClientCert bool   `yaml:"require_client_cert" env:"TLS_REQUIRE_CLIENT_CERT" default:"false"`
+
+	// admin HTTP endpoint for log levels and circuit breakers; empty disables it.
+	// It is plain HTTP and can silence logging or trip breakers, so it must
+	// stay on a loopback address unless AdminToken is set.
+	AdminAddr string `yaml:"admin_addr" env:"ADMIN_ADDR"`
+
+	// bearer token required by every admin request when set
+	AdminToken string `yaml:"admin_token" env:"ADMIN_TOKEN"`
 }
 
 // NATS message queue configuration
@@ -36,23 +52,99 @@ type NATSConfig struct {
 // logging configuration
 type LoggingConfig struct {
 	Level      string `yaml:"level" env:"LOG_LEVEL" default:"info"`
-	Format     string `yaml:"format" env:"LOG_FORMAT" default:"json"`   // json or text
-	Output     string `yaml:"output" env:"LOG_OUTPUT" default:"stdout"` // stdout, stderr, or file path
-	EnableJSON bool   `yaml:"enable_json" env:"LOG_ENABLE_JSON" default:"true"`
+	Format     string `yaml:"format" env:"LOG_FORMAT" default:"json"`           // json or text
+	Output     string `yaml:"output" env:"LOG_OUTPUT" default:"stdout"`         // stdout, stderr, or file path
+	EnableJSON bool   `yaml:"enable_json" env:"LOG_ENABLE_JSON" default:"true"` // false writes Output as text
 
-	// file losging (if output is file)
+	// file logging (if output is file)
 	MaxSize    int `yaml:"max_size" env:"LOG_MAX_SIZE" default:"100"` // MB
 	MaxBackups int `yaml:"max_backups" env:"LOG_MAX_BACKUPS" default:"10"`
 	MaxAge     int `yaml:"max_age" env:"LOG_MAX_AGE" default:"30"` // days
+
+	// several simultaneous outputs; when set, Format, Output and EnableJSON are ignored
+	Outputs []LogOutputConfig `yaml:"outputs"`
+
+	// volume control
+	Sampling  logger.SamplingConfig  `yaml:"sampling"`
+	RateLimit logger.RateLimitConfig `yaml:"rate_limit"`
+	Dedup     LogDedupConfig         `yaml:"dedup"`
+
+	// secrets hidden before entries reach any output
+	Redaction logger.RedactionConfig `yaml:"redaction"`
+}
+
+// one destination of log entries with its own format and level
+type LogOutputConfig struct {
+	Output string `yaml:"output"` // stdout, stderr, or file path
+	Format string `yaml:"format"` // json or text, default json
+	Level  string `yaml:"level"`  // can only raise logging.level
+
+	// file rotation; zero values fall back to the top-level settings
+	MaxSize    int  `yaml:"max_size"`
+	MaxBackups int  `yaml:"max_backups"`
+	MaxAge     int  `yaml:"max_age"`
+	Compress   bool `yaml:"compress"`
+}
+
+// collapses identical entries at Level or above within Window
+type LogDedupConfig struct {
+	Window time.Duration `yaml:"window"`
+	Level  string        `yaml:"level"` // default error
+}
+
+// AppConfig converts the logging section for logger.FromAppConfig
+func (c LoggingConfig) AppConfig() logger.AppConfig {
+	format := c.Format
+	if !c.EnableJSON {
+		format = "text"
+	}
+
+	app := logger.AppConfig{
+		Level:       c.Level,
+		Format:      format,
+		Output:      c.Output,
+		MaxSize:     c.MaxSize,
+		MaxBackups:  c.MaxBackups,
+		MaxAge:      c.MaxAge,
+		Sampling:    c.Sampling,
+		RateLimit:   c.RateLimit,
+		DedupWindow: c.Dedup.Window,
+		DedupLevel:  c.Dedup.Level,
+		Redaction:   c.Redaction,
+	}
+	for _, out := range c.Outputs {
+		app.Outputs = append(app.Outputs, logger.OutputConfig{
+			Output:     out.Output,
+			Format:     out.Format,
+			Level:      out.Level,
+			MaxSize:    out.MaxSize,
+			MaxBackups: out.MaxBackups,
+			MaxAge:     out.MaxAge,
+			Compress:   out.Compress,
+		})
+	}
+	return app
 }
 
 type RegistryConfig struct {
 	HeartbeatInterval time.Duration `yaml:"heartbeat_interval" env:"HEARTBEAT_INTERVAL" default:"30s"`
 	HeartbeatTimeout  time.Duration `yaml:"heartbeat_timeout" env:"HEARTBEAT_TIMEOUT" default:"90s"`
 
-	LoadBalancingStrategy string `yaml:"load_balancing_strategy" env:"LB_STRATEGY" default:"round_robin"` // round_robin, least_connections, random
+	LoadBalancingStrategy string `yaml:"load_balancing_strategy" env:"LB_STRATEGY" default:"round_robin"` // round_robin, least_connections, random, weighted_round_robin, consistent_hash
 
 	CacheTTL time.Duration `yaml:"cache_ttl" env:"CACHE_TTL" default:"5m"`
+
+	Store StoreConfig `yaml:"store"`
+}
+
+// registry persistence
+type StoreConfig struct {
+	Type string `yaml:"type" env:"REGISTRY_STORE_TYPE" default:"memory"` // memory or file
+	Path string `yaml:"path" env:"REGISTRY_STORE_PATH" default:"data/registry"`
+
+	// file store: log records between snapshots, and fsync after every write
+	SnapshotThreshold int  `yaml:"snapshot_threshold" env:"REGISTRY_SNAPSHOT_THRESHOLD" default:"1000"`
+	SyncWrites        bool `yaml:"sync_writes" env:"REGISTRY_SYNC_WRITES" default:"true"`
 }
 
 type Config struct {
@@ -69,19 +161,72 @@ type Config struct {
 	} `yaml:"features"`
 }
 
+// Validate reports every problem in the configuration at once
 func (c *Config) Validate() error {
+	problems := &errors.MultiError{}
+
 	if c.Server.Port <= 0 || c.Server.Port > 65535 {
-		return fmt.Errorf("invalid server port: %d", c.Server.Port)
+		problems.Append(errors.ValidationError("server.port",
+			fmt.Sprintf("must be between 1 and 65535, got %d", c.Server.Port)))
+	}
+
+	if c.Server.EnableTLS && (c.Server.TLSCertPath == "" || c.Server.TLSKeyPath == "") {
+		problems.Append(errors.ValidationError("server.tls_cert_path",
+			"tls_cert_path and tls_key_path are required when TLS is enabled"))
+	}
+
+	if c.Server.AdminAddr != "" && c.Server.AdminToken == "" && !IsLoopbackAddr(c.Server.AdminAddr) {
+		problems.Append(errors.ValidationError("server.admin_addr",
+			fmt.Sprintf("must be a loopback address unless admin_token is set, got '%s'", c.Server.AdminAddr)))
 	}
 
 	if c.NATS.URL == "" {
-		return fmt.Errorf("NATS URL is required")
+		problems.Append(errors.ValidationError("nats.url", "is required"))
+	}
+
+	// levels are checked by the parser the logger uses, so whatever passes
+	// here also starts the logger
+	if _, err := logger.ParseLevel(c.Logging.Level); err != nil {
+		problems.Append(errors.ValidationError("logging.level", err.Error()))
+	}
+	if _, err := logger.ParseLevel(c.Logging.Dedup.Level); err != nil {
+		problems.Append(errors.ValidationError("logging.dedup.level", err.Error()))
+	}
+
+	for i, out := range c.Logging.Outputs {
+		field := fmt.Sprintf("logging.outputs[%d]", i)
+		if out.Output == "" {
+			problems.Append(errors.ValidationError(field+".output", "is required"))
+		}
+		if _, err := logger.ParseLevel(out.Level); err != nil {
+			problems.Append(errors.ValidationError(field+".level", err.Error()))
+		}
+	}
+
+	if c.Logging.Redaction.Enabled {
+		if _, err := logger.NewRedactor(c.Logging.Redaction); err != nil {
+			problems.Append(errors.ValidationError("logging.redaction", err.Error()))
+		}
 	}
 
-	if c.Logging.Level != "debug" && c.Logging.Level != "info" &&
-		c.Logging.Level != "warn" && c.Logging.Level != "error" {
-		return fmt.Errorf("invalid log level: %s", c.Logging.Level)
+	if c.Registry.Store.Type != "memory" && c.Registry.Store.Type != "file" {
+		problems.Append(errors.ValidationError("registry.store.type",
+			fmt.Sprintf("must be memory or file, got '%s'", c.Registry.Store.Type)))
 	}
 
-	return nil
+	return problems.ErrorOrNil()
+}
+
+// IsLoopbackAddr reports whether a host:port address only accepts local
+// connections. An empty host listens on every interface.
+func IsLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
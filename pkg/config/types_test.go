@@ -0,0 +1,127 @@
+package config
+
+import (
+	"testing"
+
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/logger"
+)
+
+func validConfig() *Config {
+	cfg := &Config{}
+	cfg.Server.Port = 50051
+	cfg.NATS.URL = "nats://localhost:4222"
+	cfg.Logging.Level = "info"
+	cfg.Registry.Store.Type = "memory"
+	return cfg
+}
+
+// invalidFields returns the field of every problem reported by Validate
+func invalidFields(t *testing.T, cfg *Config) []string {
+	t.Helper()
+
+	err := cfg.Validate()
+	if err == nil {
+		return nil
+	}
+	multi, ok := err.(*errors.MultiError)
+	if !ok {
+		t.Fatalf("Validate returned %T, want *errors.MultiError", err)
+	}
+
+	var fields []string
+	for _, e := range multi.Errors {
+		if !errors.Is(e, errors.CodeValidation) {
+			t.Errorf("problem %v is not a validation error", e)
+		}
+		field, _ := e.(*errors.Error).GetMetadata("field")
+		fields = append(fields, field.(string))
+	}
+	return fields
+}
+
+func TestValidateValid(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := validConfig()
+	cfg.Server.Port = 70000
+	cfg.Server.EnableTLS = true
+	cfg.NATS.URL = ""
+	cfg.Logging.Level = "loud"
+	cfg.Logging.Outputs = []LogOutputConfig{{Level: "info"}, {Output: "stderr", Level: "trace"}}
+	cfg.Logging.Dedup.Level = "errors"
+	cfg.Registry.Store.Type = "sql"
+
+	want := []string{
+		"server.port",
+		"server.tls_cert_path",
+		"nats.url",
+		"logging.level",
+		"logging.dedup.level",
+		"logging.outputs[0].output",
+		"logging.outputs[1].level",
+		"registry.store.type",
+	}
+	got := invalidFields(t, cfg)
+	if len(got) != len(want) {
+		t.Fatalf("problems = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("problem %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	if code := cfg.Validate().(*errors.MultiError).Code(); code != errors.CodeValidation {
+		t.Errorf("aggregate code = %s, want VALIDATION_ERROR", code)
+	}
+}
+
+func TestValidateAdminAddr(t *testing.T) {
+	tests := []struct {
+		addr, token string
+		ok          bool
+	}{
+		{"", "", true},
+		{"127.0.0.1:9090", "", true},
+		{"localhost:9090", "", true},
+		{"[::1]:9090", "", true},
+		{"0.0.0.0:9090", "", false},
+		{":9090", "", false},
+		{"10.0.0.5:9090", "", false},
+		{"0.0.0.0:9090", "s3cret", true},
+	}
+	for _, tt := range tests {
+		cfg := validConfig()
+		cfg.Server.AdminAddr = tt.addr
+		cfg.Server.AdminToken = tt.token
+
+		fields := invalidFields(t, cfg)
+		if tt.ok && len(fields) != 0 {
+			t.Errorf("%q with token %q: problems %v", tt.addr, tt.token, fields)
+		}
+		if !tt.ok && (len(fields) != 1 || fields[0] != "server.admin_addr") {
+			t.Errorf("%q without a token: problems %v, want server.admin_addr", tt.addr, fields)
+		}
+	}
+}
+
+func TestValidateAcceptsLoggerLevels(t *testing.T) {
+	for _, level := range []string{"debug", "warning", "WARN", "fatal", "panic"} {
+		cfg := validConfig()
+		cfg.Logging.Level = level
+		cfg.Logging.Dedup.Level = level
+		cfg.Logging.Outputs = []LogOutputConfig{{Output: "stderr", Level: level}}
+
+		if fields := invalidFields(t, cfg); len(fields) != 0 {
+			t.Errorf("level %q: problems %v", level, fields)
+		}
+		if _, err := logger.ConfigFromApp(cfg.Logging.AppConfig()); err != nil {
+			t.Errorf("level %q passed Validate but the logger rejects it: %v", level, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// CircuitBreakerRegistry holds named circuit breakers so they can be shared
+// between callers and inspected at runtime
+type CircuitBreakerRegistry struct {
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+	defaults CircuitBreakerConfig
+}
+
+// DefaultBreakers is the process-wide circuit breaker registry
+var DefaultBreakers = NewCircuitBreakerRegistry(DefaultCircuitBreakerConfig())
+
+// NewCircuitBreakerRegistry creates a registry whose Get creates missing
+// breakers from defaults
+func NewCircuitBreakerRegistry(defaults CircuitBreakerConfig) *CircuitBreakerRegistry {
+	return &CircuitBreakerRegistry{
+		breakers: make(map[string]*CircuitBreaker),
+		defaults: defaults,
+	}
+}
+
+// Get returns the named breaker, creating it with the registry defaults
+func (r *CircuitBreakerRegistry) Get(name string) *CircuitBreaker {
+	r.mu.RLock()
+	cb, ok := r.breakers[name]
+	r.mu.RUnlock()
+	if ok {
+		return cb
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cb, ok := r.breakers[name]; ok {
+		return cb
+	}
+	cb = NewCircuitBreakerWithConfig(name, r.defaults)
+	r.breakers[name] = cb
+	return cb
+}
+
+// Register adds a breaker, replacing any breaker with the same name
+func (r *CircuitBreakerRegistry) Register(cb *CircuitBreaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breakers[cb.Name()] = cb
+}
+
+// Lookup returns the named breaker if it exists
+func (r *CircuitBreakerRegistry) Lookup(name string) (*CircuitBreaker, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cb, ok := r.breakers[name]
+	return cb, ok
+}
+
+// Remove deletes the named breaker
+func (r *CircuitBreakerRegistry) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.breakers, name)
+}
+
+// Snapshots returns every breaker's snapshot sorted by name
+func (r *CircuitBreakerRegistry) Snapshots() []CircuitBreakerSnapshot {
+	r.mu.RLock()
+	breakers := make([]*CircuitBreaker, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		breakers = append(breakers, cb)
+	}
+	r.mu.RUnlock()
+
+	snaps := make([]CircuitBreakerSnapshot, 0, len(breakers))
+	for _, cb := range breakers {
+		snaps = append(snaps, cb.Snapshot())
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Name < snaps[j].Name })
+	return snaps
+}
+
+// Handler serves the registry for an admin endpoint:
+//
+//	GET  /             all breakers
+//	GET  /?name=NAME   one breaker
+//	POST /?name=NAME   reset (close) one breaker
+func (r *CircuitBreakerRegistry) Handler() http.Handler {
+	return NewErrorHandler(func(w http.ResponseWriter, req *http.Request) error {
+		name := req.URL.Query().Get("name")
+
+		switch req.Method {
+		case http.MethodGet:
+			if name == "" {
+				return writeJSON(w, r.Snapshots())
+			}
+			cb, ok := r.Lookup(name)
+			if !ok {
+				return NotFoundError("circuit breaker", name)
+			}
+			return writeJSON(w, cb.Snapshot())
+
+		case http.MethodPost:
+			if name == "" {
+				return ValidationError("name", "is required")
+			}
+			cb, ok := r.Lookup(name)
+			if !ok {
+				return NotFoundError("circuit breaker", name)
+			}
+			cb.Reset()
+			return writeJSON(w, cb.Snapshot())
+
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			return Newf(CodeMethodNotAllowed, "method %s not allowed", req.Method)
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return Wrap(err, CodeInternalError, "cannot encode response")
+	}
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.Write(append(body, '\n'))
+	return nil
+}
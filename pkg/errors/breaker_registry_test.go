@@ -0,0 +1,62 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerRegistryHandler(t *testing.T) {
+	r := NewCircuitBreakerRegistry(CircuitBreakerConfig{FailureThreshold: 1, ResetTimeout: time.Minute})
+	r.Get("nats").Execute(func() error { return New(CodeNetworkError, "down") })
+	r.Get("store")
+	handler := r.Handler()
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/")
+	var all []CircuitBreakerSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &all); err != nil || len(all) != 2 {
+		t.Fatalf("GET / = %s, want both breakers", rec.Body)
+	}
+	if all[0].Name != "nats" || all[0].State != "open" {
+		t.Errorf("first breaker = %+v, want nats open", all[0])
+	}
+
+	rec = serve(http.MethodPost, "/?name=nats")
+	var reset CircuitBreakerSnapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &reset); err != nil || reset.State != "closed" {
+		t.Errorf("POST reset = %s, want nats closed", rec.Body)
+	}
+
+	tests := []struct {
+		method, target string
+		status         int
+	}{
+		{http.MethodGet, "/?name=store", http.StatusOK},
+		{http.MethodGet, "/?name=missing", http.StatusNotFound},
+		{http.MethodPost, "/?name=missing", http.StatusNotFound},
+		{http.MethodPost, "/", http.StatusBadRequest},
+		{http.MethodDelete, "/?name=nats", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if rec := serve(tt.method, tt.target); rec.Code != tt.status {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, rec.Code, tt.status)
+		}
+	}
+
+	rec = serve(http.MethodPut, "/")
+	if allow := rec.Header().Get("Allow"); allow != "GET, POST" {
+		t.Errorf("Allow = %q, want GET, POST", allow)
+	}
+	var body errorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error.Code != CodeMethodNotAllowed {
+		t.Errorf("body = %s, want METHOD_NOT_ALLOWED", rec.Body)
+	}
+}
@@ -0,0 +1,69 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// catalogEntry is a CodeInfo as published to API consumers
+type catalogEntry struct {
+	Code        ErrorCode         `json:"code"`
+	HTTPStatus  int               `json:"http_status"`
+	GRPCCode    string            `json:"grpc_code"`
+	Retryable   bool              `json:"retryable"`
+	Description string            `json:"description"`
+	Messages    map[string]string `json:"messages,omitempty"`
+}
+
+func catalog() []catalogEntry {
+	infos := Codes()
+	entries := make([]catalogEntry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, catalogEntry{
+			Code:        info.Code,
+			HTTPStatus:  info.HTTPStatus,
+			GRPCCode:    info.GRPCCode.String(),
+			Retryable:   info.Retryable,
+			Description: info.Description,
+			Messages:    info.Messages,
+		})
+	}
+	return entries
+}
+
+// WriteCatalogJSON writes every registered error code as a JSON array
+func WriteCatalogJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(catalog())
+}
+
+// WriteCatalogMarkdown writes every registered error code as a Markdown table
+func WriteCatalogMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| Code | HTTP | gRPC | Retryable | Description | Languages |\n")
+	b.WriteString("|------|------|------|-----------|-------------|-----------|\n")
+
+	for _, entry := range catalog() {
+		langs := make([]string, 0, len(entry.Messages))
+		for lang := range entry.Messages {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+
+		retryable := "no"
+		if entry.Retryable {
+			retryable = "yes"
+		}
+
+		fmt.Fprintf(&b, "| `%s` | %d | %s | %s | %s | %s |\n",
+			entry.Code, entry.HTTPStatus, entry.GRPCCode, retryable,
+			strings.ReplaceAll(entry.Description, "|", `\|`), strings.Join(langs, ", "))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestWriteCatalogJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCatalogJSON(&buf); err != nil {
+		t.Fatalf("WriteCatalogJSON: %v", err)
+	}
+
+	var entries []catalogEntry
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatalf("catalog is not JSON: %v", err)
+	}
+	if len(entries) != len(Codes()) {
+		t.Fatalf("%d entries, want one per registered code (%d)", len(entries), len(Codes()))
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i-1].Code >= entries[i].Code {
+			t.Fatalf("entries not sorted: %s before %s", entries[i-1].Code, entries[i].Code)
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.Code == CodeTimeout {
+			if entry.HTTPStatus != 504 || entry.GRPCCode != "DeadlineExceeded" || !entry.Retryable {
+				t.Errorf("TIMEOUT entry = %+v", entry)
+			}
+			return
+		}
+	}
+	t.Error("TIMEOUT missing from the catalog")
+}
+
+func TestWriteCatalogMarkdown(t *testing.T) {
+	registerTestCode(t, CodeInfo{
+		Code:        "TEST_CATALOG_PIPE",
+		HTTPStatus:  409,
+		GRPCCode:    codes.Aborted,
+		Description: "Either this | or that",
+		Messages:    map[string]string{"fr": "x", "de": "y"},
+	})
+
+	var buf bytes.Buffer
+	if err := WriteCatalogMarkdown(&buf); err != nil {
+		t.Fatalf("WriteCatalogMarkdown: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(Codes())+2 {
+		t.Fatalf("%d lines, want a header, a separator and one row per code", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "| Code | HTTP | gRPC |") {
+		t.Errorf("header = %q", lines[0])
+	}
+
+	want := "| `TEST_CATALOG_PIPE` | 409 | Aborted | no | Either this \\| or that | de, fr |"
+	if !strings.Contains(buf.String(), want+"\n") {
+		t.Errorf("catalog has no row %q:\n%s", want, buf.String())
+	}
+	if !strings.Contains(buf.String(), "| `SERVICE_UNAVAILABLE` | 503 | Unavailable | yes |") {
+		t.Errorf("catalog has no SERVICE_UNAVAILABLE row:\n%s", buf.String())
+	}
+}
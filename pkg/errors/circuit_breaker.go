@@ -0,0 +1,424 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	StateClosed CircuitState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// number of buckets the sliding window is divided into
+const windowBuckets = 10
+
+// CircuitBreakerConfig holds circuit breaker configuration.
+// The circuit opens when either trip condition is met.
+type CircuitBreakerConfig struct {
+	// Consecutive failures that open the circuit (0 disables)
+	FailureThreshold int `json:"failure_threshold" yaml:"failure_threshold"`
+
+	// Failure rate over the sliding window that opens the circuit,
+	// between 0 and 1 (0 disables). The rate is only considered once the
+	// window holds at least MinRequests calls.
+	FailureRate float64       `json:"failure_rate" yaml:"failure_rate"`
+	Window      time.Duration `json:"window" yaml:"window"`
+	MinRequests int           `json:"min_requests" yaml:"min_requests"`
+
+	// How long the circuit stays open before trial calls are let through
+	ResetTimeout time.Duration `json:"reset_timeout" yaml:"reset_timeout"`
+
+	// Trial calls allowed in flight while half-open
+	HalfOpenMaxAttempts int `json:"half_open_max_attempts" yaml:"half_open_max_attempts"`
+
+	// Decides which errors count as failures (nil means any error)
+	IsFailure func(error) bool `json:"-" yaml:"-"`
+}
+
+// DefaultCircuitBreakerConfig returns sensible default circuit breaker config
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold:    5,
+		FailureRate:         0.5,
+		Window:              60 * time.Second,
+		MinRequests:         20,
+		ResetTimeout:        30 * time.Second,
+		HalfOpenMaxAttempts: 3,
+	}
+}
+
+// CircuitCounts are call counts since the circuit last changed state
+type CircuitCounts struct {
+	Requests            int `json:"requests"`
+	Successes           int `json:"successes"`
+	Failures            int `json:"failures"`
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	Rejected            int `json:"rejected"`
+}
+
+// CircuitBreakerSnapshot describes a breaker for inspection
+type CircuitBreakerSnapshot struct {
+	Name            string        `json:"name"`
+	State           string        `json:"state"`
+	Counts          CircuitCounts `json:"counts"`
+	FailureRate     float64       `json:"failure_rate"`
+	LastStateChange time.Time     `json:"last_state_change"`
+}
+
+// StateChangeFunc is called after a breaker changes state
+type StateChangeFunc func(name string, from, to CircuitState)
+
+// CircuitBreaker implements circuit breaker pattern. It is safe for
+// concurrent use.
+type CircuitBreaker struct {
+	name   string
+	config CircuitBreakerConfig
+
+	mu               sync.Mutex
+	state            circuitStateData
+	window           *failureWindow
+	halfOpenInFlight int
+	listeners        []StateChangeFunc
+}
+
+type circuitStateData struct {
+	state      CircuitState
+	generation uint64 // bumped on every transition so stale results are ignored
+	changedAt  time.Time
+	counts     CircuitCounts
+}
+
+type stateChange struct {
+	from, to CircuitState
+}
+
+// NewCircuitBreaker creates a new circuit breaker that opens after
+// failureThreshold consecutive failures
+func NewCircuitBreaker(name string, failureThreshold int, resetTimeout time.Duration) *CircuitBreaker {
+	return NewCircuitBreakerWithConfig(name, CircuitBreakerConfig{
+		FailureThreshold:    failureThreshold,
+		ResetTimeout:        resetTimeout,
+		HalfOpenMaxAttempts: 3,
+	})
+}
+
+// NewCircuitBreakerWithConfig creates a circuit breaker from config
+func NewCircuitBreakerWithConfig(name string, config CircuitBreakerConfig) *CircuitBreaker {
+	if config.HalfOpenMaxAttempts <= 0 {
+		config.HalfOpenMaxAttempts = 1
+	}
+
+	cb := &CircuitBreaker{
+		name:   name,
+		config: config,
+		state:  circuitStateData{state: StateClosed, changedAt: time.Now()},
+	}
+	if config.FailureRate > 0 && config.Window > 0 {
+		cb.window = newFailureWindow(config.Window)
+	}
+	return cb
+}
+
+// Name returns the breaker name
+func (cb *CircuitBreaker) Name() string {
+	return cb.name
+}
+
+// OnStateChange registers a callback for state transitions. Callbacks run
+// synchronously after the breaker lock is released.
+func (cb *CircuitBreaker) OnStateChange(fn StateChangeFunc) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.listeners = append(cb.listeners, fn)
+}
+
+// State returns the current state
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	change := cb.refresh(time.Now())
+	state := cb.state.state
+	cb.mu.Unlock()
+
+	cb.notify(change)
+	return state
+}
+
+// Counts returns call counts since the last state change
+func (cb *CircuitBreaker) Counts() CircuitCounts {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state.counts
+}
+
+// Snapshot returns the state, counts and window failure rate
+func (cb *CircuitBreaker) Snapshot() CircuitBreakerSnapshot {
+	now := time.Now()
+
+	cb.mu.Lock()
+	change := cb.refresh(now)
+	snap := CircuitBreakerSnapshot{
+		Name:            cb.name,
+		State:           cb.state.state.String(),
+		Counts:          cb.state.counts,
+		LastStateChange: cb.state.changedAt,
+	}
+	if cb.window != nil {
+		_, _, snap.FailureRate = cb.window.stats(now)
+	}
+	cb.mu.Unlock()
+
+	cb.notify(change)
+	return snap
+}
+
+// Reset closes the circuit and clears all counts
+func (cb *CircuitBreaker) Reset() {
+	now := time.Now()
+
+	cb.mu.Lock()
+	change := cb.setState(StateClosed, now)
+	if cb.window != nil {
+		cb.window.reset()
+	}
+	cb.mu.Unlock()
+
+	cb.notify(change)
+}
+
+// Execute runs a function with circuit breaker protection. A panic in fn
+// is recorded as a failure and then propagated.
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	generation, err := cb.before()
+	if err != nil {
+		return err
+	}
+
+	// release a half-open slot even if fn never returns
+	defer func() {
+		if rec := recover(); rec != nil {
+			cb.after(generation, true)
+			panic(rec)
+		}
+	}()
+
+	err = fn()
+	cb.after(generation, cb.isFailure(err))
+
+	return err
+}
+
+// before admits or rejects a call and returns the generation it belongs to
+func (cb *CircuitBreaker) before() (uint64, error) {
+	cb.mu.Lock()
+	change := cb.refresh(time.Now())
+
+	var err error
+	switch cb.state.state {
+	case StateOpen:
+		err = New(CodeServiceUnavailable,
+			fmt.Sprintf("circuit breaker '%s' is open", cb.name))
+	case StateHalfOpen:
+		if cb.halfOpenInFlight >= cb.config.HalfOpenMaxAttempts {
+			err = New(CodeServiceUnavailable,
+				fmt.Sprintf("circuit breaker '%s' half-open attempts exhausted", cb.name))
+		} else {
+			cb.halfOpenInFlight++
+		}
+	}
+
+	if err != nil {
+		cb.state.counts.Rejected++
+		err = AddMetadata(err, "circuit_breaker", cb.name)
+	} else {
+		cb.state.counts.Requests++
+	}
+	generation := cb.state.generation
+	cb.mu.Unlock()
+
+	cb.notify(change)
+	return generation, err
+}
+
+// after records the result of a call admitted by before
+func (cb *CircuitBreaker) after(generation uint64, failed bool) {
+	now := time.Now()
+
+	cb.mu.Lock()
+	if cb.window != nil {
+		cb.window.record(now, failed)
+	}
+
+	// the circuit changed state while the call ran; its result is stale
+	if generation != cb.state.generation {
+		cb.mu.Unlock()
+		return
+	}
+
+	var change *stateChange
+	counts := &cb.state.counts
+	if failed {
+		counts.Failures++
+		counts.ConsecutiveFailures++
+	} else {
+		counts.Successes++
+		counts.ConsecutiveFailures = 0
+	}
+
+	switch cb.state.state {
+	case StateClosed:
+		if failed && cb.shouldTrip(now) {
+			change = cb.setState(StateOpen, now)
+		}
+	case StateHalfOpen:
+		cb.halfOpenInFlight--
+		if failed {
+			// Failed again, go back to open
+			change = cb.setState(StateOpen, now)
+		} else {
+			// Success, close the circuit
+			change = cb.setState(StateClosed, now)
+			if cb.window != nil {
+				cb.window.reset()
+			}
+		}
+	}
+	cb.mu.Unlock()
+
+	cb.notify(change)
+}
+
+func (cb *CircuitBreaker) isFailure(err error) bool {
+	if cb.config.IsFailure != nil {
+		return cb.config.IsFailure(err)
+	}
+	return err != nil
+}
+
+// shouldTrip expects cb.mu to be held
+func (cb *CircuitBreaker) shouldTrip(now time.Time) bool {
+	if cb.config.FailureThreshold > 0 &&
+		cb.state.counts.ConsecutiveFailures >= cb.config.FailureThreshold {
+		return true
+	}
+
+	if cb.window != nil {
+		requests, _, rate := cb.window.stats(now)
+		if requests >= cb.config.MinRequests && rate >= cb.config.FailureRate {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refresh moves an open circuit to half-open once the reset timeout has
+// passed. Expects cb.mu to be held.
+func (cb *CircuitBreaker) refresh(now time.Time) *stateChange {
+	if cb.state.state == StateOpen && now.Sub(cb.state.changedAt) > cb.config.ResetTimeout {
+		return cb.setState(StateHalfOpen, now)
+	}
+	return nil
+}
+
+// setState expects cb.mu to be held
+func (cb *CircuitBreaker) setState(state CircuitState, now time.Time) *stateChange {
+	from := cb.state.state
+	cb.state = circuitStateData{
+		state:      state,
+		generation: cb.state.generation + 1,
+		changedAt:  now,
+	}
+	cb.halfOpenInFlight = 0
+
+	if from == state {
+		return nil
+	}
+	return &stateChange{from: from, to: state}
+}
+
+// notify runs the state change callbacks without holding cb.mu
+func (cb *CircuitBreaker) notify(change *stateChange) {
+	if change == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	listeners := cb.listeners
+	cb.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(cb.name, change.from, change.to)
+	}
+}
+
+// failureWindow counts calls in time buckets covering the last window
+type failureWindow struct {
+	bucketSize time.Duration
+	buckets    [windowBuckets]windowBucket
+}
+
+type windowBucket struct {
+	start    time.Time
+	requests int
+	failures int
+}
+
+func newFailureWindow(window time.Duration) *failureWindow {
+	size := window / windowBuckets
+	if size <= 0 {
+		size = 1
+	}
+	return &failureWindow{bucketSize: size}
+}
+
+func (w *failureWindow) record(now time.Time, failed bool) {
+	start := now.Truncate(w.bucketSize)
+	b := &w.buckets[(start.UnixNano()/int64(w.bucketSize))%windowBuckets]
+	if !b.start.Equal(start) {
+		*b = windowBucket{start: start}
+	}
+
+	b.requests++
+	if failed {
+		b.failures++
+	}
+}
+
+func (w *failureWindow) stats(now time.Time) (requests, failures int, rate float64) {
+	oldest := now.Truncate(w.bucketSize).Add(-w.bucketSize * (windowBuckets - 1))
+	for _, b := range w.buckets {
+		if b.start.Before(oldest) {
+			continue
+		}
+		requests += b.requests
+		failures += b.failures
+	}
+
+	if requests > 0 {
+		rate = float64(failures) / float64(requests)
+	}
+	return requests, failures, rate
+}
+
+func (w *failureWindow) reset() {
+	w.buckets = [windowBuckets]windowBucket{}
+}
@@ -0,0 +1,169 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+var errFailed = fmt.Errorf("failed")
+
+func fail() error    { return errFailed }
+func succeed() error { return nil }
+
+// expire makes an open breaker eligible for half-open without sleeping
+func expire(cb *CircuitBreaker) {
+	cb.mu.Lock()
+	cb.state.changedAt = cb.state.changedAt.Add(-cb.config.ResetTimeout - time.Millisecond)
+	cb.mu.Unlock()
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	cb := NewCircuitBreaker("test", 3, time.Minute)
+
+	var changes []string
+	cb.OnStateChange(func(_ string, from, to CircuitState) {
+		changes = append(changes, from.String()+">"+to.String())
+	})
+
+	cb.Execute(fail)
+	cb.Execute(fail)
+	cb.Execute(succeed) // resets the consecutive count
+	cb.Execute(fail)
+	cb.Execute(fail)
+	if cb.State() != StateClosed {
+		t.Fatal("opened without 3 consecutive failures")
+	}
+
+	cb.Execute(fail)
+	if cb.State() != StateOpen {
+		t.Fatal("still closed after 3 consecutive failures")
+	}
+
+	called := false
+	err := cb.Execute(func() error { called = true; return nil })
+	if called || !Is(err, CodeServiceUnavailable) {
+		t.Fatalf("open circuit ran fn or returned %v", err)
+	}
+	if got := cb.Counts().Rejected; got != 1 {
+		t.Errorf("rejected = %d, want 1", got)
+	}
+
+	expire(cb)
+	if cb.State() != StateHalfOpen {
+		t.Fatal("not half-open after the reset timeout")
+	}
+	cb.Execute(fail)
+	if cb.State() != StateOpen {
+		t.Fatal("half-open failure did not reopen the circuit")
+	}
+
+	expire(cb)
+	if err := cb.Execute(succeed); err != nil {
+		t.Fatalf("half-open trial: %v", err)
+	}
+	if cb.State() != StateClosed {
+		t.Fatal("half-open success did not close the circuit")
+	}
+
+	want := "[closed>open open>half_open half_open>open open>half_open half_open>closed]"
+	if got := fmt.Sprint(changes); got != want {
+		t.Errorf("changes = %s, want %s", got, want)
+	}
+}
+
+func TestCircuitBreakerHalfOpenLimit(t *testing.T) {
+	cb := NewCircuitBreakerWithConfig("test", CircuitBreakerConfig{
+		FailureThreshold:    1,
+		ResetTimeout:        time.Minute,
+		HalfOpenMaxAttempts: 2,
+	})
+	cb.Execute(fail)
+	expire(cb)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go cb.Execute(func() error {
+			started <- struct{}{}
+			<-release
+			return nil
+		})
+	}
+	<-started
+	<-started
+
+	err := cb.Execute(succeed)
+	if !Is(err, CodeServiceUnavailable) {
+		t.Errorf("third half-open call = %v, want it rejected", err)
+	}
+	close(release)
+}
+
+func TestCircuitBreakerPanicReleasesHalfOpenSlot(t *testing.T) {
+	cb := NewCircuitBreakerWithConfig("test", CircuitBreakerConfig{
+		FailureThreshold:    1,
+		ResetTimeout:        time.Minute,
+		HalfOpenMaxAttempts: 1,
+	})
+	cb.Execute(fail)
+	expire(cb)
+
+	func() {
+		defer func() {
+			if rec := recover(); rec != "boom" {
+				t.Errorf("recovered %v, want the original panic", rec)
+			}
+		}()
+		cb.Execute(func() error { panic("boom") })
+	}()
+
+	if cb.State() != StateOpen {
+		t.Fatal("panic in a half-open trial was not recorded as a failure")
+	}
+
+	expire(cb)
+	if err := cb.Execute(succeed); err != nil {
+		t.Fatalf("trial after a panic: %v", err)
+	}
+	if cb.State() != StateClosed {
+		t.Error("circuit did not close after a successful trial")
+	}
+}
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	cb := NewCircuitBreakerWithConfig("test", CircuitBreakerConfig{
+		FailureRate:  0.5,
+		Window:       time.Minute,
+		MinRequests:  4,
+		ResetTimeout: time.Minute,
+	})
+
+	cb.Execute(fail)
+	cb.Execute(succeed)
+	cb.Execute(fail)
+	if cb.State() != StateClosed {
+		t.Fatal("opened below MinRequests")
+	}
+
+	cb.Execute(fail)
+	if cb.State() != StateOpen {
+		t.Fatalf("still closed at a failure rate of %v", cb.Snapshot().FailureRate)
+	}
+}
+
+func TestCircuitBreakerIsFailure(t *testing.T) {
+	cb := NewCircuitBreakerWithConfig("test", CircuitBreakerConfig{
+		FailureThreshold: 1,
+		ResetTimeout:     time.Minute,
+		IsFailure:        func(err error) bool { return err != nil && !Is(err, CodeNotFound) },
+	})
+
+	err := cb.Execute(func() error { return New(CodeNotFound, "missing") })
+	if !Is(err, CodeNotFound) {
+		t.Errorf("err = %v, want it returned unchanged", err)
+	}
+	if cb.State() != StateClosed {
+		t.Error("an error excluded by IsFailure opened the circuit")
+	}
+}
@@ -0,0 +1,302 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TimeoutHeader tells HTTP servers how many milliseconds the caller will wait
+const TimeoutHeader = "X-Request-Timeout-Ms"
+
+// ResilienceConfig configures retries and circuit breaking for outbound calls
+type ResilienceConfig struct {
+	Retry RetryConfig `json:"retry" yaml:"retry"`
+
+	// Registry the per-target breakers come from (nil means DefaultBreakers)
+	Breakers *CircuitBreakerRegistry `json:"-" yaml:"-"`
+
+	// Deadline applied to calls whose context has none (0 means none).
+	// For HTTP it bounds each attempt, since the response body outlives the call.
+	DefaultTimeout time.Duration `json:"default_timeout" yaml:"default_timeout"`
+
+	// Full gRPC method names ("/package.Service/Method") that are safe to
+	// send more than once. Other unary calls are attempted only once.
+	IdempotentMethods []string `json:"idempotent_methods" yaml:"idempotent_methods"`
+}
+
+// DefaultResilienceConfig returns sensible defaults. Callers sharing the
+// returned config also share its retry budget.
+func DefaultResilienceConfig() ResilienceConfig {
+	retry := DefaultRetryConfig()
+	retry.Budget = NewRetryBudget(10, 1)
+
+	return ResilienceConfig{
+		Retry:          retry,
+		DefaultTimeout: 30 * time.Second,
+	}
+}
+
+func (c ResilienceConfig) breaker(name string) *CircuitBreaker {
+	if c.Breakers != nil {
+		return c.Breakers.Get(name)
+	}
+	return DefaultBreakers.Get(name)
+}
+
+// GRPCClientInterceptor retries failed calls to the methods listed in
+// IdempotentMethods and guards each target with a circuit breaker named
+// "grpc:<target>". gRPC forwards the deadline to the server itself; the
+// request ID, trace, tenant and scenario in the context are forwarded as
+// metadata.
+func GRPCClientInterceptor(config ResilienceConfig) grpc.UnaryClientInterceptor {
+	idempotent := make(map[string]bool, len(config.IdempotentMethods))
+	for _, method := range config.IdempotentMethods {
+		idempotent[method] = true
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+
+		retry := config.Retry
+		if !idempotent[method] {
+			retry.MaxAttempts = 1
+		}
+
+		if _, ok := ctx.Deadline(); !ok && config.DefaultTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, config.DefaultTimeout)
+			defer cancel()
+		}
+		ctx = outgoingGRPCContext(ctx)
+
+		return callResilient(ctx, config, retry, "grpc:"+cc.Target(), func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+// RoundTripper retries idempotent HTTP requests and guards each host with a
+// circuit breaker named "http:<host>". Responses with status 429, 502, 503
+// or 504 are retried; the last response is returned if retries run out.
+type RoundTripper struct {
+	next   http.RoundTripper
+	config ResilienceConfig
+}
+
+// NewRoundTripper wraps next (nil means http.DefaultTransport)
+func NewRoundTripper(next http.RoundTripper, config ResilienceConfig) *RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &RoundTripper{next: next, config: config}
+}
+
+func (t *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	retry := t.config.Retry
+	if !isReplayable(req) {
+		retry.MaxAttempts = 1
+	}
+
+	// the response body outlives this call, so attempt contexts are
+	// cancelled when the body is closed rather than when the attempt ends
+	attemptTimeout := retry.AttemptTimeout
+	retry.AttemptTimeout = 0
+
+	ctx := req.Context()
+	if _, ok := ctx.Deadline(); !ok && t.config.DefaultTimeout > 0 {
+		attemptTimeout = minPositive(attemptTimeout, t.config.DefaultTimeout)
+	}
+
+	var resp *http.Response
+	err := callResilient(ctx, t.config, retry, "http:"+req.URL.Host, func(ctx context.Context) error {
+		if resp != nil {
+			discard(resp)
+			resp = nil
+		}
+
+		cancel := context.CancelFunc(func() {})
+		if attemptTimeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, attemptTimeout)
+		}
+
+		attemptReq, err := cloneRequest(ctx, req)
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		r, err := t.next.RoundTrip(attemptReq)
+		if err != nil {
+			cancel()
+			return transportError(err)
+		}
+		r.Body = &cancelOnClose{ReadCloser: r.Body, cancel: cancel}
+
+		resp = r
+		return httpStatusError(r)
+	})
+
+	if resp != nil {
+		return resp, nil
+	}
+	return nil, err
+}
+
+// callResilient runs fn through the target's breaker with retries. Errors
+// the caller caused (e.g. NOT_FOUND) and breaker rejections end the retry
+// loop without counting as breaker failures.
+func callResilient(ctx context.Context, config ResilienceConfig, retry RetryConfig,
+	name string, fn func(ctx context.Context) error) error {
+
+	cb := config.breaker(name)
+
+	var final error
+	err := RetryWithContext(ctx, retry, func(ctx context.Context, attempt int) error {
+		var callerErr error
+		err := cb.Execute(func() error {
+			err := fn(ctx)
+			if err != nil && !isServiceFailure(err) {
+				callerErr = err
+				return nil
+			}
+			return err
+		})
+
+		switch {
+		case callerErr != nil:
+			final = callerErr
+			return nil
+		case isBreakerRejection(err):
+			final = err
+			return nil
+		default:
+			return err
+		}
+	})
+
+	if final != nil {
+		return final
+	}
+	return err
+}
+
+// isServiceFailure reports whether err says the remote side is unhealthy
+func isServiceFailure(err error) bool {
+	if errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled {
+		return false
+	}
+	return ToError(err).IsServerError()
+}
+
+func isBreakerRejection(err error) bool {
+	appErr, ok := err.(*Error)
+	if !ok || appErr.Code != CodeServiceUnavailable {
+		return false
+	}
+	_, ok = appErr.GetMetadata("circuit_breaker")
+	return ok
+}
+
+// httpStatusError converts retryable response statuses into errors
+func httpStatusError(resp *http.Response) error {
+	var code ErrorCode
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		code = CodeServiceUnavailable
+	case http.StatusBadGateway:
+		code = CodeNetworkError
+	case http.StatusGatewayTimeout:
+		code = CodeTimeout
+	default:
+		return nil
+	}
+
+	err := Newf(code, "%s %s returned %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.Status)
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		err = AddMetadata(err, MetadataRetryAfter, after)
+	}
+	return err
+}
+
+func transportError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Wrap(err, CodeTimeout, "request timed out")
+	}
+	return Wrap(err, CodeNetworkError, "request failed")
+}
+
+// isReplayable reports whether req may be sent more than once
+func isReplayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get("Idempotency-Key") != ""
+	}
+}
+
+// cloneRequest prepares req for one attempt with a fresh body and the
+// remaining time in TimeoutHeader
+func cloneRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	r := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, Wrap(err, CodeInternalError, "cannot rewind request body")
+		}
+		r.Body = body
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		ms := time.Until(deadline).Milliseconds()
+		if ms < 1 {
+			ms = 1
+		}
+		r.Header.Set(TimeoutHeader, strconv.FormatInt(ms, 10))
+	}
+	return r, nil
+}
+
+// discard drains a response that will not be returned so the connection
+// can be reused
+func discard(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+func minPositive(a, b time.Duration) time.Duration {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
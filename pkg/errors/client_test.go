@@ -0,0 +1,50 @@
+package errors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCClientInterceptorRetriesIdempotentOnly(t *testing.T) {
+	cc, err := grpc.Dial("passthrough:///registry", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer cc.Close()
+
+	config := ResilienceConfig{
+		Retry:             RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		Breakers:          NewCircuitBreakerRegistry(CircuitBreakerConfig{FailureThreshold: 100, ResetTimeout: time.Minute}),
+		IdempotentMethods: []string{"/registry.ServiceRegistry/Discover"},
+	}
+	interceptor := GRPCClientInterceptor(config)
+
+	tests := []struct {
+		method   string
+		attempts int
+	}{
+		{"/registry.ServiceRegistry/Discover", 3},
+		{"/registry.ServiceRegistry/Register", 1},
+	}
+	for _, tt := range tests {
+		attempts := 0
+		invoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+			attempts++
+			return status.Error(codes.Unavailable, "registry restarting")
+		}
+
+		err := interceptor(context.Background(), tt.method, nil, nil, cc, invoker)
+		if status.Code(err) != codes.Unavailable {
+			t.Errorf("%s: err = %v, want Unavailable", tt.method, err)
+		}
+		if attempts != tt.attempts {
+			t.Errorf("%s: %d attempts, want %d", tt.method, attempts, tt.attempts)
+		}
+	}
+}
@@ -1,5 +1,13 @@
 package errors
 
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
 type ErrorCode string
 
 const (
@@ -9,6 +17,7 @@ const (
 	CodeAlreadyExists    ErrorCode = "ALREADY_EXISTS"
 	CodePermissionDenied ErrorCode = "PERMISSION_DENIED"
 	CodeUnauthorized     ErrorCode = "UNAUTHORIZED"
+	CodeMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
 
 	CodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
 	CodeServiceNotFound    ErrorCode = "SERVICE_NOT_FOUND"
@@ -22,25 +31,150 @@ const (
 	CodeValidation  ErrorCode = "VALIDATION_ERROR"
 )
 
+// CodeInfo describes how an error code is reported and handled
+type CodeInfo struct {
+	Code        ErrorCode  `json:"code"`
+	HTTPStatus  int        `json:"http_status"`
+	GRPCCode    codes.Code `json:"grpc_code"`
+	Retryable   bool       `json:"retryable"`
+	Description string     `json:"description"`
+
+	// Message templates by language tag ("de", "pt-br"), rendered with
+	// text/template against the error metadata, e.g. "{{.resource}} fehlt"
+	Messages map[string]string `json:"messages,omitempty"`
+}
+
+var codeRegistry = struct {
+	sync.RWMutex
+	codes map[ErrorCode]CodeInfo
+}{codes: make(map[ErrorCode]CodeInfo)}
+
+func init() {
+	for _, info := range []CodeInfo{
+		{CodeInternalError, 500, codes.Internal, false, "Unexpected failure inside the service", nil},
+		{CodeInvalidArgument, 400, codes.InvalidArgument, false, "The request is malformed or has invalid values", nil},
+		{CodeNotFound, 404, codes.NotFound, false, "The requested resource does not exist", nil},
+		{CodeAlreadyExists, 409, codes.AlreadyExists, false, "The resource being created already exists", nil},
+		{CodePermissionDenied, 403, codes.PermissionDenied, false, "The caller may not perform this operation", nil},
+		{CodeUnauthorized, 401, codes.Unauthenticated, false, "The caller is not authenticated", nil},
+		{CodeMethodNotAllowed, 405, codes.Unimplemented, false, "The endpoint does not support this HTTP method", nil},
+		{CodeServiceUnavailable, 503, codes.Unavailable, true, "The service is temporarily unable to handle the request", nil},
+		{CodeServiceNotFound, 404, codes.NotFound, false, "No registered service matches the request", nil},
+		{CodeServiceExists, 409, codes.AlreadyExists, false, "A service with this ID is already registered", nil},
+		{CodeNetworkError, 503, codes.Unavailable, true, "A downstream call failed at the network level", nil},
+		{CodeTimeout, 504, codes.DeadlineExceeded, true, "The operation did not finish in time", nil},
+		{CodeConnectionLost, 503, codes.Unavailable, true, "The connection to a dependency was lost", nil},
+		{CodeConfigError, 500, codes.FailedPrecondition, false, "The service is misconfigured", nil},
+		{CodeValidation, 400, codes.InvalidArgument, false, "One or more fields failed validation", nil},
+	} {
+		MustRegisterCode(info)
+	}
+}
+
+// RegisterCode adds a code to the registry so it gets the given HTTP
+// status, gRPC code and retry behaviour. Codes can only be registered once.
+func RegisterCode(info CodeInfo) error {
+	if info.Code == "" {
+		return New(CodeInvalidArgument, "error code must not be empty")
+	}
+	if info.HTTPStatus < 100 || info.HTTPStatus > 599 {
+		return Newf(CodeInvalidArgument, "invalid HTTP status %d for error code %s", info.HTTPStatus, info.Code)
+	}
+
+	codeRegistry.Lock()
+	defer codeRegistry.Unlock()
+
+	if _, exists := codeRegistry.codes[info.Code]; exists {
+		return AlreadyExistsError("error code", string(info.Code))
+	}
+
+	info.Messages = normalizeMessages(info.Messages)
+	codeRegistry.codes[info.Code] = info
+	return nil
+}
+
+// MustRegisterCode is RegisterCode for package initialization; it panics on error
+func MustRegisterCode(info CodeInfo) {
+	if err := RegisterCode(info); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterMessage adds or replaces the message template of a registered
+// code for one language
+func RegisterMessage(code ErrorCode, lang, template string) error {
+	codeRegistry.Lock()
+	defer codeRegistry.Unlock()
+
+	info, ok := codeRegistry.codes[code]
+	if !ok {
+		return NotFoundError("error code", string(code))
+	}
+
+	messages := make(map[string]string, len(info.Messages)+1)
+	for k, v := range info.Messages {
+		messages[k] = v
+	}
+	messages[strings.ToLower(lang)] = template
+	info.Messages = messages
+
+	codeRegistry.codes[code] = info
+	return nil
+}
+
+// LookupCode returns the registered description of a code
+func LookupCode(code ErrorCode) (CodeInfo, bool) {
+	codeRegistry.RLock()
+	defer codeRegistry.RUnlock()
+
+	info, ok := codeRegistry.codes[code]
+	return info, ok
+}
+
+// Codes returns every registered code sorted by name
+func Codes() []CodeInfo {
+	codeRegistry.RLock()
+	infos := make([]CodeInfo, 0, len(codeRegistry.codes))
+	for _, info := range codeRegistry.codes {
+		infos = append(infos, info)
+	}
+	codeRegistry.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Code < infos[j].Code })
+	return infos
+}
+
+// RetryableCodes returns every registered code marked retryable
+func RetryableCodes() []ErrorCode {
+	var retryable []ErrorCode
+	for _, info := range Codes() {
+		if info.Retryable {
+			retryable = append(retryable, info.Code)
+		}
+	}
+	return retryable
+}
+
+// HTTPStatus returns the registered HTTP status, or 500 for unknown codes
 func (c ErrorCode) HTTPStatus() int {
-	switch c {
-	case CodeInvalidArgument, CodeValidation:
-		return 400
-	case CodeUnauthorized:
-		return 401
-	case CodePermissionDenied:
-		return 403
-	case CodeNotFound:
-		return 404
-	case CodeAlreadyExists, CodeServiceExists:
-		return 409
-	case CodeInternalError, CodeServiceUnavailable:
-		return 500
-	case CodeNetworkError, CodeTimeout, CodeConnectionLost:
-		return 503
-	default:
-		return 500
+	if info, ok := LookupCode(c); ok {
+		return info.HTTPStatus
+	}
+	return 500
+}
+
+// GRPCCode returns the registered gRPC status code, or Unknown for unknown codes
+func (c ErrorCode) GRPCCode() codes.Code {
+	if info, ok := LookupCode(c); ok {
+		return info.GRPCCode
 	}
+	return codes.Unknown
+}
+
+// IsRetryable reports whether the code is registered as retryable
+func (c ErrorCode) IsRetryable() bool {
+	info, ok := LookupCode(c)
+	return ok && info.Retryable
 }
 
 func (c ErrorCode) IsClientError() bool {
@@ -52,3 +186,14 @@ func (c ErrorCode) IsServerError() bool {
 	status := c.HTTPStatus()
 	return status >= 500 && status < 600
 }
+
+func normalizeMessages(messages map[string]string) map[string]string {
+	if len(messages) == 0 {
+		return nil
+	}
+	normalized := make(map[string]string, len(messages))
+	for lang, tmpl := range messages {
+		normalized[strings.ToLower(lang)] = tmpl
+	}
+	return normalized
+}
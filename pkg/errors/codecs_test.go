@@ -0,0 +1,138 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// registerTestCode registers info for the length of the test
+func registerTestCode(t *testing.T, info CodeInfo) {
+	t.Helper()
+
+	if err := RegisterCode(info); err != nil {
+		t.Fatalf("RegisterCode %s: %v", info.Code, err)
+	}
+	t.Cleanup(func() {
+		codeRegistry.Lock()
+		delete(codeRegistry.codes, info.Code)
+		codeRegistry.Unlock()
+	})
+}
+
+func TestBuiltinCodes(t *testing.T) {
+	tests := []struct {
+		code      ErrorCode
+		http      int
+		retryable bool
+	}{
+		{CodeServiceUnavailable, 503, true},
+		{CodeTimeout, 504, true},
+		{CodeNotFound, 404, false},
+		{CodeValidation, 400, false},
+		{CodeMethodNotAllowed, 405, false},
+		{"NOT_REGISTERED", 500, false},
+	}
+	for _, tt := range tests {
+		if got := tt.code.HTTPStatus(); got != tt.http {
+			t.Errorf("%s: HTTP status = %d, want %d", tt.code, got, tt.http)
+		}
+		if got := tt.code.IsRetryable(); got != tt.retryable {
+			t.Errorf("%s: retryable = %v, want %v", tt.code, got, tt.retryable)
+		}
+	}
+}
+
+func TestRegisterCode(t *testing.T) {
+	const code ErrorCode = "TEST_QUOTA_EXCEEDED"
+	registerTestCode(t, CodeInfo{
+		Code:        code,
+		HTTPStatus:  429,
+		GRPCCode:    codes.ResourceExhausted,
+		Retryable:   true,
+		Description: "The tenant used up its quota",
+		Messages:    map[string]string{"DE": "Kontingent von {{.tenant}} erschöpft"},
+	})
+
+	appErr := AddMetadata(New(code, "quota exceeded"), "tenant", "acme")
+	if appErr.HTTPStatus() != 429 || status.Code(appErr) != codes.ResourceExhausted || !code.IsRetryable() {
+		t.Errorf("registered code reports HTTP %d, gRPC %s, retryable %v",
+			appErr.HTTPStatus(), status.Code(appErr), code.IsRetryable())
+	}
+	if got := appErr.LocalizedMessage("de"); got != "Kontingent von acme erschöpft" {
+		t.Errorf("LocalizedMessage(de) = %q, want the template registered as DE", got)
+	}
+
+	found := false
+	for _, c := range RetryableCodes() {
+		found = found || c == code
+	}
+	if !found {
+		t.Error("registered retryable code missing from RetryableCodes")
+	}
+
+	if err := RegisterCode(CodeInfo{Code: code, HTTPStatus: 400}); !Is(err, CodeAlreadyExists) {
+		t.Errorf("duplicate registration: err = %v, want ALREADY_EXISTS", err)
+	}
+	if err := RegisterCode(CodeInfo{HTTPStatus: 400}); !Is(err, CodeInvalidArgument) {
+		t.Errorf("empty code: err = %v, want INVALID_ARGUMENT", err)
+	}
+	if err := RegisterCode(CodeInfo{Code: "TEST_BAD_STATUS", HTTPStatus: 42}); !Is(err, CodeInvalidArgument) {
+		t.Errorf("bad status: err = %v, want INVALID_ARGUMENT", err)
+	}
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	const code ErrorCode = "TEST_ZONE_MISSING"
+	registerTestCode(t, CodeInfo{
+		Code:       code,
+		HTTPStatus: 404,
+		GRPCCode:   codes.NotFound,
+		Messages: map[string]string{
+			"de":    "Zone {{.zone}} fehlt",
+			"pt":    "Zona {{.zone}} não existe",
+			"pt-br": "A zona {{.zone}} não existe",
+		},
+	})
+	if err := RegisterMessage(code, "FR", "Zone {{.zone}} introuvable ({{.code}})"); err != nil {
+		t.Fatalf("RegisterMessage: %v", err)
+	}
+	if err := RegisterMessage("TEST_NOT_REGISTERED", "fr", "x"); !Is(err, CodeNotFound) {
+		t.Errorf("RegisterMessage for an unknown code: err = %v, want NOT_FOUND", err)
+	}
+
+	err := AddMetadata(New(code, "zone eu-9 is missing"), "zone", "eu-9")
+	tests := []struct {
+		lang, want string
+	}{
+		{"de", "Zone eu-9 fehlt"},
+		{"de-AT", "Zone eu-9 fehlt"},
+		{"pt-BR", "A zona eu-9 não existe"},
+		{"pt_PT", "Zona eu-9 não existe"},
+		{"fr", "Zone eu-9 introuvable (TEST_ZONE_MISSING)"},
+		{"ja", "zone eu-9 is missing"},
+	}
+	for _, tt := range tests {
+		if got := err.LocalizedMessage(tt.lang); got != tt.want {
+			t.Errorf("LocalizedMessage(%s) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+
+	// a template referring to missing metadata falls back to the message
+	if got := New(code, "zone is missing").LocalizedMessage("de"); got != "zone is missing" {
+		t.Errorf("without metadata: %q, want the plain message", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "ja, de;q=0.8")
+	rec, body := writeError(t, req, err)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if detail, _ := body["error"].(map[string]interface{}); detail["message"] != "Zone eu-9 fehlt" {
+		t.Errorf("message = %v, want the first language with a template", detail["message"])
+	}
+}
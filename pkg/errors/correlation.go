@@ -0,0 +1,140 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"upm-simple/pkg/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// headers (and lowercase gRPC metadata keys) carrying correlation IDs.
+// TraceParentHeader follows the W3C Trace Context format.
+const (
+	TraceParentHeader = "traceparent"
+	TenantHeader      = "X-Tenant-ID"
+	ScenarioHeader    = "X-Scenario-ID"
+)
+
+// GRPCContextInterceptor stores the request ID, trace, tenant and scenario
+// from incoming metadata in the context, generating a request ID if the
+// caller sent none, and echoes the request ID in the response header
+func GRPCContextInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	ctx = incomingGRPCContext(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(RequestIDHeader), logger.RequestIDFromContext(ctx)))
+	return handler(ctx, req)
+}
+
+// GRPCStreamContextInterceptor is GRPCContextInterceptor for streams
+func GRPCStreamContextInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	ctx := incomingGRPCContext(ss.Context())
+	ss.SetHeader(metadata.Pairs(strings.ToLower(RequestIDHeader), logger.RequestIDFromContext(ctx)))
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// contextStream replaces the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func incomingGRPCContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return correlationContext(ctx, func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+}
+
+// outgoingGRPCContext forwards the correlation IDs in ctx to the server
+func outgoingGRPCContext(ctx context.Context) context.Context {
+	var pairs []string
+	add := func(key, value string) {
+		if value != "" {
+			pairs = append(pairs, strings.ToLower(key), value)
+		}
+	}
+
+	add(RequestIDHeader, logger.RequestIDFromContext(ctx))
+	add(TraceParentHeader, traceParent(ctx))
+	add(TenantHeader, logger.TenantFromContext(ctx))
+	add(ScenarioHeader, logger.ScenarioFromContext(ctx))
+
+	if len(pairs) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// httpRequestContext stores the correlation IDs from request headers in
+// the request context
+func httpRequestContext(r *http.Request) context.Context {
+	return correlationContext(r.Context(), r.Header.Get)
+}
+
+func correlationContext(ctx context.Context, get func(key string) string) context.Context {
+	id := get(RequestIDHeader)
+	if id == "" {
+		id = logger.RequestIDFromContext(ctx)
+	}
+	if id == "" {
+		id = newID(8)
+	}
+	ctx = logger.ContextWithRequestID(ctx, id)
+
+	if traceID, spanID, ok := parseTraceParent(get(TraceParentHeader)); ok {
+		ctx = logger.ContextWithTrace(ctx, traceID, spanID)
+	}
+	if tenant := get(TenantHeader); tenant != "" {
+		ctx = logger.ContextWithTenant(ctx, tenant)
+	}
+	if scenario := get(ScenarioHeader); scenario != "" {
+		ctx = logger.ContextWithScenario(ctx, scenario)
+	}
+
+	return ctx
+}
+
+// parseTraceParent reads "version-traceid-parentid-flags", e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func parseTraceParent(value string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	if !isHex(parts[1]) || !isHex(parts[2]) ||
+		parts[1] == strings.Repeat("0", 32) || parts[2] == strings.Repeat("0", 16) {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func traceParent(ctx context.Context) string {
+	traceID, spanID := logger.TraceFromContext(ctx)
+	if traceID == "" || spanID == "" {
+		return ""
+	}
+	return "00-" + traceID + "-" + spanID + "-01"
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
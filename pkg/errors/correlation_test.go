@@ -0,0 +1,146 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"upm-simple/pkg/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID      = "00f067aa0ba902b7"
+	testTraceParent = "00-" + testTraceID + "-" + testSpanID + "-01"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		value string
+		ok    bool
+	}{
+		{testTraceParent, true},
+		{" " + testTraceParent + " ", true},
+		{"", false},
+		{"00-" + testTraceID + "-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testSpanID + "-01", false},
+		{"00-00000000000000000000000000000000-" + testSpanID + "-01", false},
+		{"00-" + testTraceID + "-0000000000000000-01", false},
+		{"00-" + testTraceID + "-" + testSpanID + "z-01", false},
+	}
+	for _, tt := range tests {
+		traceID, spanID, ok := parseTraceParent(tt.value)
+		if ok != tt.ok {
+			t.Errorf("parseTraceParent(%q) ok = %v, want %v", tt.value, ok, tt.ok)
+		}
+		if ok && (traceID != testTraceID || spanID != testSpanID) {
+			t.Errorf("parseTraceParent(%q) = %s, %s", tt.value, traceID, spanID)
+		}
+	}
+}
+
+func TestPanicRecoveryCorrelation(t *testing.T) {
+	var ctx context.Context
+	handler := PanicRecovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/mock", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	req.Header.Set(TraceParentHeader, testTraceParent)
+	req.Header.Set(TenantHeader, "acme")
+	req.Header.Set(ScenarioHeader, "slow-db")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if id := logger.RequestIDFromContext(ctx); id != "req-1" {
+		t.Errorf("request ID = %q, want req-1", id)
+	}
+	if traceID, spanID := logger.TraceFromContext(ctx); traceID != testTraceID || spanID != testSpanID {
+		t.Errorf("trace = %s/%s, want the traceparent IDs", traceID, spanID)
+	}
+	if logger.TenantFromContext(ctx) != "acme" || logger.ScenarioFromContext(ctx) != "slow-db" {
+		t.Errorf("tenant/scenario = %q/%q", logger.TenantFromContext(ctx), logger.ScenarioFromContext(ctx))
+	}
+	if got := rec.Header().Get(RequestIDHeader); got != "req-1" {
+		t.Errorf("response %s = %q, want req-1", RequestIDHeader, got)
+	}
+
+	// a request without an ID gets one, echoed in the response
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mock", nil))
+	id := logger.RequestIDFromContext(ctx)
+	if id == "" || rec.Header().Get(RequestIDHeader) != id {
+		t.Errorf("generated ID %q, response header %q", id, rec.Header().Get(RequestIDHeader))
+	}
+}
+
+func TestPanicRecoveryErrorKeepsRequestID(t *testing.T) {
+	handler := PanicRecovery(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/mock", nil)
+	req.Header.Set(RequestIDHeader, "req-9")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var body errorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	if rec.Code != http.StatusInternalServerError || body.Error.RequestID != "req-9" {
+		t.Errorf("status %d, request_id %q; want 500 with the caller's request ID", rec.Code, body.Error.RequestID)
+	}
+}
+
+func TestGRPCContextInterceptor(t *testing.T) {
+	md := metadata.Pairs(
+		"x-request-id", "req-1",
+		TraceParentHeader, testTraceParent,
+		"x-tenant-id", "acme",
+		"x-scenario-id", "slow-db",
+	)
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	var got context.Context
+	_, err := GRPCContextInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/Method"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			got = ctx
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("interceptor: %v", err)
+	}
+
+	if logger.RequestIDFromContext(got) != "req-1" || logger.TenantFromContext(got) != "acme" ||
+		logger.ScenarioFromContext(got) != "slow-db" {
+		t.Errorf("context fields = %v", logger.ContextFields(got))
+	}
+	if traceID, _ := logger.TraceFromContext(got); traceID != testTraceID {
+		t.Errorf("trace ID = %q", traceID)
+	}
+
+	// the client side forwards the same IDs
+	out, _ := metadata.FromOutgoingContext(outgoingGRPCContext(got))
+	want := map[string]string{
+		"x-request-id":    "req-1",
+		TraceParentHeader: testTraceParent,
+		"x-tenant-id":     "acme",
+		"x-scenario-id":   "slow-db",
+	}
+	for k, v := range want {
+		if values := out.Get(k); len(values) != 1 || values[0] != v {
+			t.Errorf("outgoing %s = %v, want %s", k, values, v)
+		}
+	}
+
+	if _, ok := metadata.FromOutgoingContext(outgoingGRPCContext(context.Background())); ok {
+		t.Error("metadata added for a context without IDs")
+	}
+}
@@ -2,8 +2,6 @@ package errors
 
 import (
 	"fmt"
-	"runtime"
-	"strings"
 	"time"
 )
 
@@ -16,10 +14,8 @@ type Error struct {
 	Timestamp  time.Time              `json:"timestamp"`
 	StackTrace string                 `json:"stack_trace,omitempty"`
 
-	// For debugging
-	file     string
-	line     int
-	function string
+	// Where the error was created, innermost first
+	frames []Frame
 }
 
 // New creates a new error with code and message
@@ -29,7 +25,7 @@ func New(code ErrorCode, message string) *Error {
 
 // Newf creates a new error with formatted message
 func Newf(code ErrorCode, format string, args ...interface{}) *Error {
-	return New(code, fmt.Sprintf(format, args...))
+	return newError(code, fmt.Sprintf(format, args...), nil, nil)
 }
 
 // Wrap wraps an existing error with additional context
@@ -38,21 +34,16 @@ func Wrap(err error, code ErrorCode, message string) *Error {
 		return nil
 	}
 
-	// If err is already our Error type, add to it
-	if appErr, ok := err.(*Error); ok {
-		// Create new error with same cause chain
-		newErr := newError(code, message, appErr, nil)
-		newErr.Cause = appErr
-		return newErr
-	}
-
-	// Wrap regular error
+	// Our own errors and regular errors both become the cause
 	return newError(code, message, err, nil)
 }
 
 // Wrapf wraps an existing error with formatted message
 func Wrapf(err error, code ErrorCode, format string, args ...interface{}) *Error {
-	return Wrap(err, code, fmt.Sprintf(format, args...))
+	if err == nil {
+		return nil
+	}
+	return newError(code, fmt.Sprintf(format, args...), err, nil)
 }
 
 // WithMetadata adds metadata to error
@@ -88,8 +79,8 @@ func AddMetadata(err error, key string, value interface{}) *Error {
 // Error implements the error interface
 func (e *Error) Error() string {
 	if e.Cause != nil {
-		return fmt.Sprintf("%s [%s]: %s (caused by: %v)",
-			e.Code, e.Message, e.Cause.Error())
+		return fmt.Sprintf("%s: %s (caused by: %v)",
+			e.Code, e.Message, e.Cause)
 	}
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
@@ -150,44 +141,25 @@ func (e *Error) GetStringMetadata(key string) (string, bool) {
 	return fmt.Sprintf("%v", val), true
 }
 
-// Helper function to create new error with stack trace
+// Helper function to create new error with stack trace.
+// It must be called directly by the exported constructor so that the
+// first captured frame is the constructor's caller.
 func newError(code ErrorCode, message string, cause error, metadata map[string]interface{}) *Error {
-	// Capture stack trace (skip 3 frames: newError -> New/Wrap -> caller)
-	pc := make([]uintptr, 10)
-	n := runtime.Callers(3, pc)
-	frames := runtime.CallersFrames(pc[:n])
-
-	var stackBuilder strings.Builder
-	var file, function string
-	var line int
-
-	for i := 0; ; i++ {
-		frame, more := frames.Next()
-		if i == 0 {
-			// First frame is the actual error location
-			file = frame.File
-			line = frame.Line
-			function = frame.Function
-		}
+	// skip newError and the constructor
+	frames := captureStack(2)
 
-		stackBuilder.WriteString(fmt.Sprintf("%s\n\t%s:%d\n",
-			frame.Function, frame.File, frame.Line))
-
-		if !more {
-			break
-		}
+	var stackTrace string
+	if stackEnabled.Load() {
+		stackTrace = formatStack(frames)
 	}
 
 	err := &Error{
 		Code:       code,
 		Message:    message,
 		Cause:      cause,
-		Metadata:   metadata,
 		Timestamp:  time.Now().UTC(),
-		StackTrace: stackBuilder.String(),
-		file:       file,
-		line:       line,
-		function:   function,
+		StackTrace: stackTrace,
+		frames:     frames,
 	}
 
 	// Initialize metadata map if provided
@@ -0,0 +1,151 @@
+package errors
+
+import (
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain identifies our errors in gRPC ErrorInfo details
+const ErrorDomain = "upm-simple"
+
+// ErrorInfo metadata key holding each error's message when a status
+// carries several errors
+const messageKey = "_message"
+
+// codeFromGRPC is used for statuses that carry no ErrorInfo, e.g. errors
+// raised by gRPC itself or by services outside this project
+func codeFromGRPC(c codes.Code) ErrorCode {
+	switch c {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return CodeInvalidArgument
+	case codes.Unauthenticated:
+		return CodeUnauthorized
+	case codes.PermissionDenied:
+		return CodePermissionDenied
+	case codes.NotFound:
+		return CodeNotFound
+	case codes.AlreadyExists:
+		return CodeAlreadyExists
+	case codes.Unavailable, codes.ResourceExhausted:
+		return CodeServiceUnavailable
+	case codes.DeadlineExceeded:
+		return CodeTimeout
+	case codes.FailedPrecondition:
+		return CodeConfigError
+	default:
+		return CodeInternalError
+	}
+}
+
+// GRPCStatus converts the error to a gRPC status. The error code and
+// metadata travel in an ErrorInfo detail so FromGRPCStatus can rebuild them.
+// It also lets status.FromError and status.Code understand *Error directly.
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(e.Code.GRPCCode(), e.Message)
+
+	info := errorInfo(e)
+
+	detailed, err := st.WithDetails(info)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// FromGRPCStatus rebuilds an *Error from a gRPC status received by a client.
+// Metadata values come back as strings. A status carrying several errors
+// (from a MultiError) has them rebuilt as a *MultiError cause.
+// Returns nil for an OK status.
+func FromGRPCStatus(st *status.Status) *Error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	var errs []*Error
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != ErrorDomain {
+			continue
+		}
+		errs = append(errs, fromErrorInfo(info, st.Message()))
+	}
+
+	switch len(errs) {
+	case 0:
+		return newError(codeFromGRPC(st.Code()), st.Message(), nil, nil)
+	case 1:
+		return errs[0]
+	default:
+		multi := &MultiError{}
+		for _, err := range errs {
+			multi.Append(err)
+		}
+		return newError(multi.Code(), st.Message(), multi, nil)
+	}
+}
+
+// FromGRPCError is FromGRPCStatus for an error returned by a gRPC call.
+// Errors that are not gRPC statuses are converted with ToError.
+func FromGRPCError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		return FromGRPCStatus(st)
+	}
+	return ToError(err)
+}
+
+// errorInfo carries the code and metadata of e in a status detail
+func errorInfo(e *Error) *errdetails.ErrorInfo {
+	info := &errdetails.ErrorInfo{
+		Reason:   string(e.Code),
+		Domain:   ErrorDomain,
+		Metadata: make(map[string]string, len(e.Metadata)+1),
+	}
+	for k, v := range e.Metadata {
+		info.Metadata[k] = fmt.Sprintf("%v", v)
+	}
+	return info
+}
+
+func fromErrorInfo(info *errdetails.ErrorInfo, message string) *Error {
+	if msg, ok := info.Metadata[messageKey]; ok {
+		message = msg
+	}
+
+	err := newError(ErrorCode(info.Reason), message, nil, nil)
+	for k, v := range info.Metadata {
+		if k == messageKey {
+			continue
+		}
+		if err.Metadata == nil {
+			err.Metadata = make(map[string]interface{}, len(info.Metadata))
+		}
+		err.Metadata[k] = v
+	}
+	return err
+}
+
+// fromStatusError decodes err if it is itself a gRPC status error
+func fromStatusError(err error) (*Error, bool) {
+	if _, ok := err.(interface{ GRPCStatus() *status.Status }); !ok {
+		return nil, false
+	}
+	appErr := FromGRPCStatus(status.Convert(err))
+	return appErr, appErr != nil
+}
+
+// toGRPCStatus converts an error returned by a handler. Errors that already
+// carry a gRPC status (e.g. from status.Error) keep their code.
+func toGRPCStatus(err error, appErr *Error) error {
+	if _, ok := err.(*Error); !ok {
+		if st, ok := status.FromError(err); ok {
+			return st.Err()
+		}
+	}
+	return appErr.GRPCStatus().Err()
+}
@@ -0,0 +1,140 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCCodeMapping(t *testing.T) {
+	tests := []struct {
+		code ErrorCode
+		want codes.Code
+	}{
+		{CodeInternalError, codes.Internal},
+		{CodeInvalidArgument, codes.InvalidArgument},
+		{CodeValidation, codes.InvalidArgument},
+		{CodeNotFound, codes.NotFound},
+		{CodeServiceNotFound, codes.NotFound},
+		{CodeAlreadyExists, codes.AlreadyExists},
+		{CodePermissionDenied, codes.PermissionDenied},
+		{CodeUnauthorized, codes.Unauthenticated},
+		{CodeServiceUnavailable, codes.Unavailable},
+		{CodeTimeout, codes.DeadlineExceeded},
+		{CodeConfigError, codes.FailedPrecondition},
+		{"NOT_REGISTERED", codes.Unknown},
+	}
+	for _, tt := range tests {
+		err := New(tt.code, "boom")
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("%s: gRPC code = %s, want %s", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestGRPCStatusRoundTrip(t *testing.T) {
+	sent := AddMetadata(New(CodeServiceNotFound, "no instance of api"), "service", "api")
+	AddMetadata(sent, "attempts", 3)
+
+	got := FromGRPCError(sent.GRPCStatus().Err())
+	if got.Code != CodeServiceNotFound || got.Message != "no instance of api" {
+		t.Fatalf("decoded %s %q, want SERVICE_NOT_FOUND with the original message", got.Code, got.Message)
+	}
+	if v, _ := got.GetMetadata("service"); v != "api" {
+		t.Errorf("metadata service = %v, want api", v)
+	}
+	// values cross the wire as strings
+	if v, _ := got.GetMetadata("attempts"); v != "3" {
+		t.Errorf("metadata attempts = %#v, want \"3\"", v)
+	}
+}
+
+func TestFromGRPCStatusForeign(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want ErrorCode
+	}{
+		{codes.NotFound, CodeNotFound},
+		{codes.OutOfRange, CodeInvalidArgument},
+		{codes.ResourceExhausted, CodeServiceUnavailable},
+		{codes.DeadlineExceeded, CodeTimeout},
+		{codes.Aborted, CodeInternalError},
+	}
+	for _, tt := range tests {
+		got := FromGRPCError(status.Error(tt.code, "from elsewhere"))
+		if got.Code != tt.want || got.Message != "from elsewhere" {
+			t.Errorf("%s: decoded %s %q, want %s", tt.code, got.Code, got.Message, tt.want)
+		}
+	}
+
+	if FromGRPCStatus(status.New(codes.OK, "")) != nil {
+		t.Error("OK status decoded to an error")
+	}
+	if got := FromGRPCError(fmt.Errorf("plain")); got.Code != CodeInternalError {
+		t.Errorf("plain error decoded to %s, want INTERNAL_ERROR", got.Code)
+	}
+}
+
+func TestMultiErrorGRPCRoundTrip(t *testing.T) {
+	multi := &MultiError{}
+	multi.Append(
+		ValidationError("name", "is required"),
+		New(CodeNotFound, "zone eu-9 does not exist"),
+	)
+
+	st := multi.GRPCStatus()
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("gRPC code = %s, want InvalidArgument for client errors", st.Code())
+	}
+
+	got := FromGRPCStatus(st)
+	if got.Code != CodeInvalidArgument {
+		t.Errorf("decoded code = %s, want INVALID_ARGUMENT", got.Code)
+	}
+	cause, ok := got.Cause.(*MultiError)
+	if !ok || len(cause.Errors) != 2 {
+		t.Fatalf("cause = %#v, want a MultiError of 2", got.Cause)
+	}
+
+	first, second := cause.Errors[0].(*Error), cause.Errors[1].(*Error)
+	if first.Code != CodeValidation || first.Message != "validation failed for field 'name': is required" {
+		t.Errorf("first error = %s %q", first.Code, first.Message)
+	}
+	if field, _ := first.GetMetadata("field"); field != "name" {
+		t.Errorf("first error field = %v, want name", field)
+	}
+	if second.Code != CodeNotFound || second.Message != "zone eu-9 does not exist" {
+		t.Errorf("second error = %s %q", second.Code, second.Message)
+	}
+
+	multi.Append(New(CodeTimeout, "store timed out"))
+	if code := status.Code(multi); code != codes.Internal {
+		t.Errorf("with a server error: gRPC code = %s, want Internal", code)
+	}
+}
+
+func TestGRPCErrorInterceptorStatus(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test/Method"}
+
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"app error", New(CodeNotFound, "missing"), codes.NotFound},
+		{"multi error", &MultiError{Errors: []error{ValidationError("a", "bad"), ValidationError("b", "bad")}}, codes.InvalidArgument},
+		{"status error", status.Error(codes.Aborted, "conflict"), codes.Aborted},
+		{"plain error", fmt.Errorf("plain"), codes.Internal},
+	}
+	for _, tt := range tests {
+		_, err := GRPCErrorInterceptor(context.Background(), nil, info,
+			func(context.Context, interface{}) (interface{}, error) { return nil, tt.err })
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("%s: code = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
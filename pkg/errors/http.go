@@ -0,0 +1,163 @@
+package errors
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"upm-simple/pkg/logger"
+)
+
+// RequestIDHeader carries the request ID echoed in error bodies
+const RequestIDHeader = "X-Request-ID"
+
+// HTTPErrorFormat selects the body written by WriteHTTPError
+type HTTPErrorFormat int32
+
+const (
+	// FormatJSON writes {"error": {"code": ..., "message": ...}}
+	FormatJSON HTTPErrorFormat = iota
+	// FormatProblem writes application/problem+json (RFC 7807)
+	FormatProblem
+)
+
+const (
+	contentTypeJSON    = "application/json"
+	contentTypeProblem = "application/problem+json"
+)
+
+var httpErrorFormat atomic.Int32
+
+// SetHTTPErrorFormat sets the default body format. Requests that accept
+// application/problem+json always get the RFC 7807 format.
+func SetHTTPErrorFormat(format HTTPErrorFormat) {
+	httpErrorFormat.Store(int32(format))
+}
+
+// ProblemTypeBase prefixes the problem "type" URI, e.g.
+// "https://docs.example.com/errors/" gives ".../errors/not_found".
+// When empty the type is "about:blank".
+var ProblemTypeBase = ""
+
+// errorBody is the default JSON envelope
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code      ErrorCode              `json:"code"`
+	Message   string                 `json:"message"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Errors    []errorDetail          `json:"errors,omitempty"`
+}
+
+// problemBody is an RFC 7807 problem detail with our fields as extensions
+type problemBody struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail,omitempty"`
+	Instance  string                 `json:"instance,omitempty"`
+	Code      ErrorCode              `json:"code"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Errors    []errorDetail          `json:"errors,omitempty"`
+}
+
+// encodeHTTPError returns the content type and body for err. Metadata that
+// cannot be encoded is dropped rather than failing the whole response.
+func encodeHTTPError(appErr *Error, format HTTPErrorFormat, message, requestID, instance string) (string, []byte) {
+	// every collected error is listed when err wraps a MultiError
+	var errs []errorDetail
+	if multi, ok := appErr.Cause.(*MultiError); ok {
+		errs = multi.details()
+	}
+
+	metadata := appErr.Metadata
+	for {
+		var body interface{}
+		contentType := contentTypeJSON
+
+		if format == FormatProblem {
+			contentType = contentTypeProblem
+			body = problemBody{
+				Type:      problemType(appErr.Code),
+				Title:     http.StatusText(appErr.HTTPStatus()),
+				Status:    appErr.HTTPStatus(),
+				Detail:    message,
+				Instance:  instance,
+				Code:      appErr.Code,
+				Metadata:  metadata,
+				RequestID: requestID,
+				Timestamp: appErr.Timestamp,
+				Errors:    errs,
+			}
+		} else {
+			body = errorBody{Error: errorDetail{
+				Code:      appErr.Code,
+				Message:   message,
+				Metadata:  metadata,
+				RequestID: requestID,
+				Timestamp: appErr.Timestamp,
+				Errors:    errs,
+			}}
+		}
+
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(body); err != nil && (metadata != nil || errs != nil) {
+			metadata, errs = nil, nil
+			continue
+		}
+		return contentType, buf.Bytes()
+	}
+}
+
+func problemType(code ErrorCode) string {
+	if ProblemTypeBase == "" {
+		return "about:blank"
+	}
+	return ProblemTypeBase + strings.ToLower(string(code))
+}
+
+// requestFormat picks the problem format when the client asks for it
+func requestFormat(r *http.Request) HTTPErrorFormat {
+	if r != nil && strings.Contains(r.Header.Get("Accept"), contentTypeProblem) {
+		return FormatProblem
+	}
+	return HTTPErrorFormat(httpErrorFormat.Load())
+}
+
+// requestID returns the request ID stored by PanicRecovery, the caller's
+// request ID, then one already set on the response, and otherwise
+// generates one
+func requestID(w http.ResponseWriter, r *http.Request) string {
+	if r != nil {
+		if id := logger.RequestIDFromContext(r.Context()); id != "" {
+			return id
+		}
+		if id := r.Header.Get(RequestIDHeader); id != "" {
+			return id
+		}
+	}
+	if id := w.Header().Get(RequestIDHeader); id != "" {
+		return id
+	}
+	return newID(8)
+}
+
+// newID returns n random bytes as hex, or "" if randomness is unavailable
+func newID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,133 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// writeError writes err for req and decodes the JSON body
+func writeError(t *testing.T, req *http.Request, err error) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	WriteHTTPErrorForRequest(rec, req, err)
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, rec.Body)
+	}
+	return rec, body
+}
+
+func TestWriteHTTPErrorJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/services/api", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	err := AddMetadata(New(CodeServiceNotFound, `no service named "api"`), "service", "api")
+
+	rec, body := writeError(t, req, err)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %s, want application/json", ct)
+	}
+	if id := rec.Header().Get(RequestIDHeader); id != "req-42" {
+		t.Errorf("%s header = %q, want the caller's ID", RequestIDHeader, id)
+	}
+
+	detail, _ := body["error"].(map[string]interface{})
+	if detail["code"] != "SERVICE_NOT_FOUND" || detail["message"] != `no service named "api"` {
+		t.Errorf("error = %v, want the code and the message with its quotes", detail)
+	}
+	if detail["request_id"] != "req-42" || detail["timestamp"] == nil {
+		t.Errorf("error = %v, want request_id and timestamp", detail)
+	}
+	if metadata, _ := detail["metadata"].(map[string]interface{}); metadata["service"] != "api" {
+		t.Errorf("metadata = %v, want service=api", detail["metadata"])
+	}
+}
+
+func TestWriteHTTPErrorProblem(t *testing.T) {
+	defer func(base string) { ProblemTypeBase = base }(ProblemTypeBase)
+	ProblemTypeBase = "https://errors.example.com/"
+
+	req := httptest.NewRequest(http.MethodGet, "/services/api", nil)
+	req.Header.Set("Accept", "application/problem+json")
+
+	rec, body := writeError(t, req, New(CodeNotFound, "no such service"))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Content-Type = %s, want application/problem+json", ct)
+	}
+
+	want := map[string]interface{}{
+		"type":     "https://errors.example.com/not_found",
+		"title":    "Not Found",
+		"status":   float64(404),
+		"detail":   "no such service",
+		"instance": "/services/api",
+		"code":     "NOT_FOUND",
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("%s = %v, want %v", k, body[k], v)
+		}
+	}
+	if id, _ := body["request_id"].(string); id == "" || id != rec.Header().Get(RequestIDHeader) {
+		t.Errorf("request_id = %q, want the generated ID from the header", id)
+	}
+}
+
+func TestSetHTTPErrorFormat(t *testing.T) {
+	SetHTTPErrorFormat(FormatProblem)
+	defer SetHTTPErrorFormat(FormatJSON)
+
+	rec := httptest.NewRecorder()
+	WriteHTTPError(rec, New(CodeTimeout, "slow"))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Content-Type = %s, want the configured problem format", ct)
+	}
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want 504", rec.Code)
+	}
+}
+
+func TestWriteHTTPErrorMultiError(t *testing.T) {
+	multi := &MultiError{}
+	multi.Append(ValidationError("name", "is required"), ValidationError("port", "is out of range"))
+
+	rec, body := writeError(t, nil, multi)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+
+	detail, _ := body["error"].(map[string]interface{})
+	errs, _ := detail["errors"].([]interface{})
+	if detail["code"] != "VALIDATION_ERROR" || len(errs) != 2 {
+		t.Fatalf("error = %v, want VALIDATION_ERROR listing both errors", detail)
+	}
+	if first, _ := errs[0].(map[string]interface{}); first["code"] != "VALIDATION_ERROR" {
+		t.Errorf("first listed error = %v", first)
+	}
+}
+
+func TestWriteHTTPErrorFallbacks(t *testing.T) {
+	// metadata that cannot be encoded is dropped instead of breaking the body
+	err := AddMetadata(New(CodeInternalError, "boom"), "callback", func() {})
+	rec, body := writeError(t, nil, err)
+	detail, _ := body["error"].(map[string]interface{})
+	if detail["code"] != "INTERNAL_ERROR" || detail["metadata"] != nil {
+		t.Errorf("error = %v, want INTERNAL_ERROR without metadata", detail)
+	}
+	if rec.Header().Get(RequestIDHeader) == "" {
+		t.Error("no request ID generated")
+	}
+
+	rec, body = writeError(t, nil, fmt.Errorf("plain failure"))
+	detail, _ = body["error"].(map[string]interface{})
+	if rec.Code != http.StatusInternalServerError || detail["code"] != "INTERNAL_ERROR" {
+		t.Errorf("plain error: status %d, error %v; want 500 INTERNAL_ERROR", rec.Code, detail)
+	}
+}
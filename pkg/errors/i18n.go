@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"strings"
+	"text/template"
+)
+
+// LocalizedMessage renders the message template registered for lang
+// ("pt-BR" falls back to "pt"). Without a usable template it returns
+// e.Message. Templates see the error metadata plus "code" and "message".
+func (e *Error) LocalizedMessage(lang string) string {
+	info, ok := LookupCode(e.Code)
+	if !ok || len(info.Messages) == 0 {
+		return e.Message
+	}
+
+	tmpl, ok := findMessage(info.Messages, lang)
+	if !ok {
+		return e.Message
+	}
+
+	t, err := template.New(string(e.Code)).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return e.Message
+	}
+
+	data := make(map[string]interface{}, len(e.Metadata)+2)
+	for k, v := range e.Metadata {
+		data[k] = v
+	}
+	data["code"] = string(e.Code)
+	data["message"] = e.Message
+
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return e.Message
+	}
+	return b.String()
+}
+
+// acceptLanguageMessage localizes for the first language in an
+// Accept-Language header that has a template
+func (e *Error) acceptLanguageMessage(header string) string {
+	info, ok := LookupCode(e.Code)
+	if !ok || len(info.Messages) == 0 {
+		return e.Message
+	}
+
+	for _, part := range strings.Split(header, ",") {
+		lang := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+		if _, ok := findMessage(info.Messages, lang); ok {
+			return e.LocalizedMessage(lang)
+		}
+	}
+	return e.Message
+}
+
+func findMessage(messages map[string]string, lang string) (string, bool) {
+	lang = strings.ToLower(lang)
+	if tmpl, ok := messages[lang]; ok {
+		return tmpl, true
+	}
+	if i := strings.IndexAny(lang, "-_"); i > 0 {
+		tmpl, ok := messages[lang[:i]]
+		return tmpl, ok
+	}
+	return "", false
+}
@@ -0,0 +1,139 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"upm-simple/pkg/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ChainServerOptions installs request correlation, access logging, error
+// conversion and panic recovery for both unary and streaming RPCs,
+// outermost first
+func ChainServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			GRPCContextInterceptor,
+			GRPCAccessLogInterceptor,
+			GRPCErrorInterceptor,
+			GRPCRecoveryInterceptor,
+		),
+		grpc.ChainStreamInterceptor(
+			GRPCStreamContextInterceptor,
+			GRPCStreamAccessLogInterceptor,
+			GRPCStreamErrorInterceptor,
+			GRPCStreamRecoveryInterceptor,
+		),
+	}
+}
+
+// GRPCStreamErrorInterceptor converts errors returned by stream handlers
+func GRPCStreamErrorInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	if err := handler(srv, ss); err != nil {
+		return grpcError(ss.Context(), err)
+	}
+	return nil
+}
+
+// GRPCRecoveryInterceptor recovers from panics in unary handlers
+func GRPCRecoveryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = recoverPanic(ctx, info.FullMethod, rec)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// GRPCStreamRecoveryInterceptor recovers from panics in stream handlers
+func GRPCStreamRecoveryInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = recoverPanic(ss.Context(), info.FullMethod, rec)
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+// GRPCAccessLogInterceptor logs every unary call with its peer, duration and code
+func GRPCAccessLogInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logAccess(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+// GRPCStreamAccessLogInterceptor logs every stream once it ends
+func GRPCStreamAccessLogInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	start := time.Now()
+	err := handler(srv, ss)
+	logAccess(ss.Context(), info.FullMethod, start, err)
+	return err
+}
+
+// grpcError logs a handler error and converts it to a gRPC status
+func grpcError(ctx context.Context, err error) error {
+	// a client going away or running out of time is not an application error
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	appErr := ToError(err)
+	logError(ctx, appErr)
+	return toGRPCStatus(err, appErr)
+}
+
+func recoverPanic(ctx context.Context, method string, rec interface{}) error {
+	logger.Default().WithContext(ctx).Error("panic recovered",
+		logger.FieldString("method", method),
+		logger.FieldString("panic", fmt.Sprintf("%v", rec)),
+		logger.FieldString("stack", string(debug.Stack())),
+	)
+	return New(CodeInternalError, "internal server error")
+}
+
+func logAccess(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+
+	addr := "unknown"
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+	}
+
+	fields := []logger.Field{
+		logger.FieldString("method", method),
+		logger.FieldString("peer", addr),
+		logger.FieldAny("duration", time.Since(start)),
+		logger.FieldString("code", code.String()),
+	}
+
+	log := logger.Default().WithContext(ctx)
+	switch code {
+	case codes.OK, codes.Canceled:
+		log.Info("grpc request", fields...)
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		log.Error("grpc request", fields...)
+	default:
+		log.Warn("grpc request", fields...)
+	}
+}
@@ -12,14 +12,19 @@ import (
 	"google.golang.org/grpc"
 )
 
-// PanicRecovery recovers from panics and converts to errors
+// PanicRecovery recovers from panics and converts to errors. It also
+// stores the request ID, trace, tenant and scenario from the request
+// headers in the request context for logger.WithContext.
 func PanicRecovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(httpRequestContext(r))
+		w.Header().Set(RequestIDHeader, logger.RequestIDFromContext(r.Context()))
+
 		defer func() {
 			if rec := recover(); rec != nil {
 				// Log the panic
 				stack := debug.Stack()
-				logger.Default().Error("panic recovered",
+				logger.Default().WithContext(r.Context()).Error("panic recovered",
 					logger.FieldString("url", r.URL.Path),
 					logger.FieldString("method", r.Method),
 					logger.FieldString("panic", fmt.Sprintf("%v", rec)),
@@ -28,7 +33,7 @@ func PanicRecovery(next http.Handler) http.Handler {
 
 				// Return error response
 				err := New(CodeInternalError, "internal server error")
-				WriteHTTPError(w, err)
+				WriteHTTPErrorForRequest(w, r, err)
 			}
 		}()
 
@@ -49,45 +54,54 @@ func NewErrorHandler(handler func(http.ResponseWriter, *http.Request) error) htt
 func (h *ErrorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	err := h.handler(w, r)
 	if err != nil {
-		WriteHTTPError(w, err)
+		WriteHTTPErrorForRequest(w, r, err)
 	}
 }
 
 // WriteHTTPError writes error as HTTP response
 func WriteHTTPError(w http.ResponseWriter, err error) {
+	WriteHTTPErrorForRequest(w, nil, err)
+}
+
+// WriteHTTPErrorForRequest writes error as HTTP response, taking the request
+// ID, problem URI, preferred format and language from the request
+func WriteHTTPErrorForRequest(w http.ResponseWriter, r *http.Request, err error) {
 	var appErr *Error
 
 	// Convert to our error type
 	if e, ok := err.(*Error); ok {
 		appErr = e
+	} else if _, ok := err.(*MultiError); ok {
+		appErr = ToError(err)
 	} else {
 		appErr = Wrap(err, CodeInternalError, err.Error())
 	}
 
 	// Log error
-	logError(appErr)
-
-	// Write response
-	status := appErr.HTTPStatus()
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-
-	// In production, you might want a structured error response
-	response := map[string]interface{}{
-		"error": map[string]interface{}{
-			"code":    appErr.Code,
-			"message": appErr.Message,
-		},
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
 	}
+	logError(ctx, appErr)
 
-	// Add metadata if present
-	if len(appErr.Metadata) > 0 {
-		response["error"].(map[string]interface{})["metadata"] = appErr.Metadata
+	message := appErr.Message
+	var instance string
+	if r != nil {
+		message = appErr.acceptLanguageMessage(r.Header.Get("Accept-Language"))
+		instance = r.URL.Path
 	}
 
-	// In real implementation, use JSON encoder
-	fmt.Fprintf(w, `{"error":{"code":"%s","message":"%s"}}`,
-		appErr.Code, appErr.Message)
+	id := requestID(w, r)
+	contentType, body := encodeHTTPError(appErr, requestFormat(r), message, id, instance)
+
+	// Write response
+	if id != "" {
+		w.Header().Set(RequestIDHeader, id)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(appErr.HTTPStatus())
+	w.Write(body)
 }
 
 // GRPCErrorInterceptor intercepts gRPC errors
@@ -96,12 +110,8 @@ func GRPCErrorInterceptor(ctx context.Context, req interface{},
 
 	resp, err := handler(ctx, req)
 	if err != nil {
-		// Convert to our error type
-		appErr := ToError(err)
-		logError(appErr)
-
-		// Convert to gRPC status
-		return nil, toGRPCStatus(appErr)
+		// Log and convert to gRPC status
+		return nil, grpcError(ctx, err)
 	}
 
 	return resp, nil
@@ -117,6 +127,15 @@ func ToError(err error) *Error {
 		return appErr
 	}
 
+	if multi, ok := err.(*MultiError); ok {
+		return Wrap(multi, multi.Code(), multi.summary())
+	}
+
+	// Errors from gRPC calls carry their code in the status
+	if appErr, ok := fromStatusError(err); ok {
+		return appErr
+	}
+
 	// Try to extract error code from error message
 	code := CodeInternalError
 	message := err.Error()
@@ -141,11 +160,15 @@ func ToError(err error) *Error {
 }
 
 // Helper function to log error with appropriate level
-func logError(err *Error) {
-	log := logger.Default().With(
+func logError(ctx context.Context, err *Error) {
+	log := logger.Default().WithContext(ctx).With(
 		logger.FieldString("error_code", string(err.Code)),
 		logger.FieldTime("timestamp", err.Timestamp),
+		logger.FieldString("fingerprint", err.Fingerprint()),
 	)
+	if origin := err.Origin(); origin.File != "" {
+		log = log.With(logger.FieldString("origin", fmt.Sprintf("%s:%d", origin.File, origin.Line)))
+	}
 
 	// Add metadata fields
 	for k, v := range err.Metadata {
@@ -164,11 +187,3 @@ func logError(err *Error) {
 		log.Debug("stack trace", logger.FieldString("stack", err.StackTrace))
 	}
 }
-
-// Note: gRPC imports and toGRPCStatus would be in a separate file
-// for now, we'll create a placeholder
-func toGRPCStatus(err *Error) error {
-	// This would convert to gRPC status codes
-	// For now, return the error as-is
-	return err
-}
@@ -0,0 +1,149 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+// MultiError collects several errors, e.g. every problem found while
+// validating a request. It supports errors.Is/As from the standard library
+// through Unwrap() []error.
+type MultiError struct {
+	Errors []error
+}
+
+// Append adds errors, skipping nils and flattening nested MultiErrors
+func (m *MultiError) Append(errs ...error) {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if nested, ok := err.(*MultiError); ok {
+			m.Append(nested.Errors...)
+			continue
+		}
+		m.Errors = append(m.Errors, err)
+	}
+}
+
+// ErrorOrNil returns nil when nothing was collected, so a *MultiError never
+// ends up as a non-nil error interface holding no errors
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.Errors) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error implements the error interface
+func (m *MultiError) Error() string {
+	if len(m.Errors) == 1 {
+		return m.Errors[0].Error()
+	}
+
+	var b strings.Builder
+	b.WriteString("multiple errors occurred:")
+	for i, err := range m.Errors {
+		fmt.Fprintf(&b, "\n  %d. %v", i+1, err)
+	}
+	return b.String()
+}
+
+// Unwrap returns the collected errors (for errors.Is/As compatibility)
+func (m *MultiError) Unwrap() []error {
+	return m.Errors
+}
+
+// Code returns the shared code when all errors agree. Otherwise it is
+// CodeInternalError if any error is a server error, and CodeInvalidArgument
+// when the caller is at fault for all of them.
+func (m *MultiError) Code() ErrorCode {
+	if len(m.Errors) == 0 {
+		return CodeInternalError
+	}
+
+	first := codeOf(m.Errors[0])
+	same := true
+	serverError := false
+	for _, err := range m.Errors {
+		code := codeOf(err)
+		if code != first {
+			same = false
+		}
+		if code.IsServerError() {
+			serverError = true
+		}
+	}
+
+	switch {
+	case same:
+		return first
+	case serverError:
+		return CodeInternalError
+	default:
+		return CodeInvalidArgument
+	}
+}
+
+// HTTPStatus returns the HTTP status code for the aggregate code
+func (m *MultiError) HTTPStatus() int {
+	return m.Code().HTTPStatus()
+}
+
+// MarshalJSON encodes the aggregate code and every error as a list
+func (m *MultiError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Code    ErrorCode     `json:"code"`
+		Message string        `json:"message"`
+		Errors  []errorDetail `json:"errors"`
+	}{
+		Code:    m.Code(),
+		Message: m.summary(),
+		Errors:  m.details(),
+	})
+}
+
+// GRPCStatus converts to a gRPC status with one ErrorInfo detail per error
+func (m *MultiError) GRPCStatus() *status.Status {
+	code := m.Code()
+	st := status.New(code.GRPCCode(), m.summary())
+
+	details := make([]protoiface.MessageV1, 0, len(m.Errors))
+	for _, err := range m.Errors {
+		appErr := ToError(err)
+		info := errorInfo(appErr)
+		info.Metadata[messageKey] = appErr.Message
+		details = append(details, info)
+	}
+
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+func (m *MultiError) summary() string {
+	if len(m.Errors) == 1 {
+		return ToError(m.Errors[0]).Message
+	}
+	return fmt.Sprintf("%d errors occurred", len(m.Errors))
+}
+
+func (m *MultiError) details() []errorDetail {
+	details := make([]errorDetail, 0, len(m.Errors))
+	for _, err := range m.Errors {
+		appErr := ToError(err)
+		details = append(details, errorDetail{
+			Code:      appErr.Code,
+			Message:   appErr.Message,
+			Metadata:  appErr.Metadata,
+			Timestamp: appErr.Timestamp,
+		})
+	}
+	return details
+}
@@ -0,0 +1,114 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"testing"
+)
+
+func TestMultiErrorAppend(t *testing.T) {
+	inner := &MultiError{}
+	inner.Append(New(CodeNotFound, "b"), nil)
+
+	multi := &MultiError{}
+	multi.Append(nil, New(CodeValidation, "a"), inner, io.EOF)
+
+	if len(multi.Errors) != 3 {
+		t.Fatalf("collected %d errors, want 3 with nils skipped and nesting flattened", len(multi.Errors))
+	}
+
+	want := "multiple errors occurred:\n  1. VALIDATION_ERROR: a\n  2. NOT_FOUND: b\n  3. EOF"
+	if got := multi.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestMultiErrorOrNil(t *testing.T) {
+	var nilMulti *MultiError
+	if nilMulti.ErrorOrNil() != nil {
+		t.Error("nil MultiError is a non-nil error")
+	}
+	if err := (&MultiError{}).ErrorOrNil(); err != nil {
+		t.Errorf("empty MultiError = %v, want nil", err)
+	}
+
+	if err := Combine(nil, nil); err != nil {
+		t.Errorf("Combine(nil, nil) = %v, want nil", err)
+	}
+	single := New(CodeTimeout, "slow")
+	if err := Combine(nil, single); err != single {
+		t.Errorf("Combine of one error = %v, want it unchanged", err)
+	}
+	if _, ok := Combine(single, io.EOF).(*MultiError); !ok {
+		t.Error("Combine of two errors is not a *MultiError")
+	}
+}
+
+func TestMultiErrorMatching(t *testing.T) {
+	multi := &MultiError{}
+	multi.Append(New(CodeNotFound, "missing"), io.EOF)
+
+	if !Is(multi, CodeNotFound) || Is(multi, CodeTimeout) {
+		t.Error("Is does not look at each collected error")
+	}
+	if !stderrors.Is(multi, io.EOF) {
+		t.Error("standard errors.Is does not see a collected error")
+	}
+	var appErr *Error
+	if !stderrors.As(multi, &appErr) || appErr.Code != CodeNotFound {
+		t.Errorf("standard errors.As found %v, want the NOT_FOUND error", appErr)
+	}
+}
+
+func TestMultiErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		errs []error
+		want ErrorCode
+	}{
+		{"empty", nil, CodeInternalError},
+		{"same code", []error{ValidationError("a", "bad"), ValidationError("b", "bad")}, CodeValidation},
+		{"client errors", []error{ValidationError("a", "bad"), New(CodeNotFound, "missing")}, CodeInvalidArgument},
+		{"any server error", []error{ValidationError("a", "bad"), New(CodeTimeout, "slow")}, CodeInternalError},
+		{"plain error", []error{ValidationError("a", "bad"), io.EOF}, CodeInternalError},
+	}
+	for _, tt := range tests {
+		multi := &MultiError{Errors: tt.errs}
+		if got := multi.Code(); got != tt.want {
+			t.Errorf("%s: Code() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMultiErrorJSON(t *testing.T) {
+	multi := &MultiError{}
+	multi.Append(ValidationError("name", "is required"), New(CodeNotFound, "no zone"))
+
+	data, err := json.Marshal(multi)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var body struct {
+		Code    ErrorCode `json:"code"`
+		Message string    `json:"message"`
+		Errors  []struct {
+			Code     ErrorCode              `json:"code"`
+			Message  string                 `json:"message"`
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if body.Code != CodeInvalidArgument || body.Message != "2 errors occurred" || len(body.Errors) != 2 {
+		t.Fatalf("decoded %s", data)
+	}
+	if body.Errors[0].Code != CodeValidation || body.Errors[0].Metadata["field"] != "name" {
+		t.Errorf("first error = %+v, want the validation error with its field", body.Errors[0])
+	}
+	if body.Errors[1].Code != CodeNotFound || body.Errors[1].Message != "no zone" {
+		t.Errorf("second error = %+v", body.Errors[1])
+	}
+}
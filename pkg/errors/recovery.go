@@ -4,45 +4,97 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// MetadataRetryAfter is the metadata key holding a server's retry hint.
+// The value may be a time.Duration, a number of seconds, a duration string
+// ("1.5s") or an HTTP date. Retries wait for the hint, but never longer
+// than RetryConfig.MaxDelay.
+const MetadataRetryAfter = "retry_after"
+
+// JitterStrategy randomizes retry delays so callers do not retry in lockstep
+type JitterStrategy string
+
+const (
+	// JitterNone uses the exponential delay as is
+	JitterNone JitterStrategy = "none"
+	// JitterFull picks a delay between 0 and the exponential delay
+	JitterFull JitterStrategy = "full"
+	// JitterEqual keeps half the exponential delay and randomizes the rest
+	JitterEqual JitterStrategy = "equal"
+	// JitterDecorrelated picks between the initial delay and 3x the previous delay
+	JitterDecorrelated JitterStrategy = "decorrelated"
+)
+
 // RetryConfig holds retry configuration
 type RetryConfig struct {
 	MaxAttempts  int           `json:"max_attempts" yaml:"max_attempts"`
 	InitialDelay time.Duration `json:"initial_delay" yaml:"initial_delay"`
-	MaxDelay     time.Duration `json:"max_delay" yaml:"max_delay"`
+	MaxDelay     time.Duration `json:"max_delay" yaml:"max_delay"` // also caps retry hints
 	Multiplier   float64       `json:"multiplier" yaml:"multiplier"`
 	Jitter       bool          `json:"jitter" yaml:"jitter"`
 
+	// Jitter strategy; when empty, Jitter selects full or no jitter
+	JitterStrategy JitterStrategy `json:"jitter_strategy" yaml:"jitter_strategy"`
+
+	// Timeout for each attempt (0 means only the caller's context applies)
+	AttemptTimeout time.Duration `json:"attempt_timeout" yaml:"attempt_timeout"`
+
 	// Which errors to retry (nil means all)
 	RetryableErrors []ErrorCode `json:"retryable_errors" yaml:"retryable_errors"`
+
+	// Shared budget limiting retries across callers (nil means unlimited)
+	Budget *RetryBudget `json:"-" yaml:"-"`
+
+	// Called before sleeping ahead of each retry
+	OnRetry func(attempt int, err error, delay time.Duration) `json:"-" yaml:"-"`
 }
 
 // DefaultRetryConfig returns sensible default retry config
 func DefaultRetryConfig() RetryConfig {
 	return RetryConfig{
-		MaxAttempts:  3,
-		InitialDelay: 100 * time.Millisecond,
-		MaxDelay:     10 * time.Second,
-		Multiplier:   2.0,
-		Jitter:       true,
-		RetryableErrors: []ErrorCode{
-			CodeNetworkError,
-			CodeTimeout,
-			CodeConnectionLost,
-			CodeServiceUnavailable,
-		},
+		MaxAttempts:    3,
+		InitialDelay:   100 * time.Millisecond,
+		MaxDelay:       10 * time.Second,
+		Multiplier:     2.0,
+		Jitter:         true,
+		JitterStrategy: JitterFull,
+
+		// codes registered as retryable at the time of the call
+		RetryableErrors: RetryableCodes(),
 	}
 }
 
 // Retry executes a function with retry logic
 func Retry(ctx context.Context, config RetryConfig, fn func() error) error {
+	return RetryWithContext(ctx, config, func(context.Context, int) error {
+		return fn()
+	})
+}
+
+// RetryWithContext executes fn until it succeeds, fails with a
+// non-retryable error, runs out of attempts or budget, or ctx is done.
+// fn receives a per-attempt context and the attempt number, starting at 1.
+// Non-retryable errors are returned unchanged.
+func RetryWithContext(ctx context.Context, config RetryConfig,
+	fn func(ctx context.Context, attempt int) error) error {
+
+	maxAttempts := config.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
 	var lastErr error
+	var delay time.Duration
 
-	for attempt := 0; attempt < config.MaxAttempts; attempt++ {
-		// Execute function
-		err := fn()
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err := runAttempt(ctx, config, attempt, fn)
 		if err == nil {
 			return nil // Success
 		}
@@ -51,12 +103,11 @@ func Retry(ctx context.Context, config RetryConfig, fn func() error) error {
 
 		// Check if error is retryable
 		if !isRetryableError(err, config.RetryableErrors) {
-			return Wrapf(err, CodeInternalError,
-				"non-retryable error after %d attempts", attempt+1)
+			return err
 		}
 
 		// Check if we should stop (last attempt or context cancelled)
-		if attempt == config.MaxAttempts-1 {
+		if attempt == maxAttempts {
 			break
 		}
 
@@ -64,112 +115,112 @@ func Retry(ctx context.Context, config RetryConfig, fn func() error) error {
 			return Wrapf(err, CodeTimeout, "context cancelled during retry")
 		}
 
-		// Calculate delay with exponential backoff
-		delay := calculateDelay(config, attempt)
+		if config.Budget != nil && !config.Budget.Withdraw() {
+			return Wrapf(err, codeOf(err), "retry budget exhausted after %d attempts", attempt)
+		}
+
+		// Calculate delay, honouring any hint from the server up to MaxDelay
+		// so a large Retry-After cannot stall the caller
+		delay = calculateDelay(config, attempt-1, delay)
+		if hint, ok := retryAfter(err); ok && hint > delay {
+			if config.MaxDelay > 0 && hint > config.MaxDelay {
+				hint = config.MaxDelay
+			}
+			delay = hint
+		}
+
+		// Give up early when the wait would outlive the caller's deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return Wrapf(err, CodeTimeout, "retry delay %v exceeds context deadline", delay)
+		}
+
+		if config.OnRetry != nil {
+			config.OnRetry(attempt, err, delay)
+		}
 
 		// Wait before retry
+		timer := time.NewTimer(delay)
 		select {
-		case <-time.After(delay):
+		case <-timer.C:
 			// Continue to next attempt
 		case <-ctx.Done():
+			timer.Stop()
 			return Wrapf(err, CodeTimeout, "context cancelled during retry delay")
 		}
 	}
 
-	return Wrapf(lastErr, CodeInternalError,
-		"failed after %d attempts", config.MaxAttempts)
+	return Wrapf(lastErr, codeOf(lastErr),
+		"failed after %d attempts", maxAttempts)
 }
 
-// CircuitBreaker implements circuit breaker pattern
-type CircuitBreaker struct {
-	name                string
-	failureThreshold    int
-	resetTimeout        time.Duration
-	halfOpenMaxAttempts int
-
-	state            circuitState
-	failureCount     int
-	lastFailure      time.Time
-	halfOpenAttempts int
-}
+// runAttempt calls fn with the attempt timeout applied. An attempt that
+// runs out of its own time becomes a CodeTimeout error so it is retried.
+func runAttempt(ctx context.Context, config RetryConfig, attempt int,
+	fn func(ctx context.Context, attempt int) error) error {
 
-type circuitState int
+	if config.AttemptTimeout <= 0 {
+		return fn(ctx, attempt)
+	}
 
-const (
-	stateClosed circuitState = iota
-	stateOpen
-	stateHalfOpen
-)
+	attemptCtx, cancel := context.WithTimeout(ctx, config.AttemptTimeout)
+	defer cancel()
 
-// NewCircuitBreaker creates a new circuit breaker
-func NewCircuitBreaker(name string, failureThreshold int, resetTimeout time.Duration) *CircuitBreaker {
-	return &CircuitBreaker{
-		name:                name,
-		failureThreshold:    failureThreshold,
-		resetTimeout:        resetTimeout,
-		halfOpenMaxAttempts: 3,
-		state:               stateClosed,
+	err := fn(attemptCtx, attempt)
+	if err != nil && ctx.Err() == nil && attemptCtx.Err() == context.DeadlineExceeded {
+		if _, ok := err.(*Error); !ok {
+			err = Wrapf(err, CodeTimeout, "attempt %d timed out after %v", attempt, config.AttemptTimeout)
+		}
 	}
+	return err
 }
 
-// Execute runs a function with circuit breaker protection
-func (cb *CircuitBreaker) Execute(fn func() error) error {
-	// Check circuit state
-	switch cb.state {
-	case stateOpen:
-		if time.Since(cb.lastFailure) > cb.resetTimeout {
-			// Move to half-open state
-			cb.state = stateHalfOpen
-			cb.halfOpenAttempts = 0
-		} else {
-			return New(CodeServiceUnavailable,
-				fmt.Sprintf("circuit breaker '%s' is open", cb.name))
-		}
-	case stateHalfOpen:
-		if cb.halfOpenAttempts >= cb.halfOpenMaxAttempts {
-			cb.state = stateOpen
-			cb.lastFailure = time.Now()
-			return New(CodeServiceUnavailable,
-				fmt.Sprintf("circuit breaker '%s' half-open attempts exhausted", cb.name))
-		}
-	}
+// RetryBudget is a token bucket shared by callers so that retries cannot
+// multiply load on a struggling dependency. Each retry spends one token;
+// tokens refill at a fixed rate up to the bucket size.
+type RetryBudget struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	refillRate float64 // tokens per second
+	last       time.Time
+}
 
-	// Execute function
-	err := fn()
+// NewRetryBudget creates a full budget of maxTokens retries that refills at
+// refillPerSecond
+func NewRetryBudget(maxTokens int, refillPerSecond float64) *RetryBudget {
+	return &RetryBudget{
+		tokens:     float64(maxTokens),
+		maxTokens:  float64(maxTokens),
+		refillRate: refillPerSecond,
+		last:       time.Now(),
+	}
+}
 
-	// Update circuit state
-	cb.updateState(err)
+// Withdraw takes a token for one retry, reporting false if none is left
+func (b *RetryBudget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
-	return err
+	b.refill(time.Now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
 }
 
-func (cb *CircuitBreaker) updateState(err error) {
-	switch cb.state {
-	case stateClosed:
-		if err != nil {
-			cb.failureCount++
-			if cb.failureCount >= cb.failureThreshold {
-				cb.state = stateOpen
-				cb.lastFailure = time.Now()
-			}
-		} else {
-			cb.failureCount = 0 // Reset on success
-		}
+// Available returns the number of whole retries left
+func (b *RetryBudget) Available() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
-	case stateHalfOpen:
-		cb.halfOpenAttempts++
-		if err != nil {
-			// Failed again, go back to open
-			cb.state = stateOpen
-			cb.lastFailure = time.Now()
-			cb.failureCount = cb.failureThreshold
-		} else {
-			// Success, close the circuit
-			cb.state = stateClosed
-			cb.failureCount = 0
-			cb.halfOpenAttempts = 0
-		}
-	}
+	b.refill(time.Now())
+	return int(b.tokens)
+}
+
+func (b *RetryBudget) refill(now time.Time) {
+	b.tokens = math.Min(b.maxTokens, b.tokens+now.Sub(b.last).Seconds()*b.refillRate)
+	b.last = now
 }
 
 // Helper functions
@@ -179,38 +230,99 @@ func isRetryableError(err error, retryableCodes []ErrorCode) bool {
 		return true // All errors are retryable
 	}
 
-	// Check if error matches any retryable code
-	var appErr *Error
-	if e, ok := err.(*Error); ok {
-		appErr = e
-	} else {
-		// Wrap regular error to check
-		appErr = Wrap(err, CodeInternalError, err.Error())
+	// Check if error or its cause chain matches any retryable code
+	return Is(err, retryableCodes...)
+}
+
+// codeOf returns the code of err, keeping it when err is wrapped again
+func codeOf(err error) ErrorCode {
+	var code ErrorCode
+	if As(err, &code) {
+		return code
 	}
+	return CodeInternalError
+}
+
+// retryAfter reads the retry hint from the metadata of err or its causes
+func retryAfter(err error) (time.Duration, bool) {
+	for err != nil {
+		appErr, ok := err.(*Error)
+		if !ok {
+			appErr, ok = fromStatusError(err)
+		}
+		if !ok {
+			return 0, false
+		}
 
-	for _, code := range retryableCodes {
-		if appErr.Code == code {
-			return true
+		if val, ok := appErr.GetMetadata(MetadataRetryAfter); ok {
+			return parseRetryAfter(val)
 		}
+		err = appErr.Cause
+	}
+	return 0, false
+}
+
+func parseRetryAfter(val interface{}) (time.Duration, bool) {
+	switch v := val.(type) {
+	case time.Duration:
+		return v, v > 0
+	case int:
+		return time.Duration(v) * time.Second, v > 0
+	case int64:
+		return time.Duration(v) * time.Second, v > 0
+	case float64:
+		return time.Duration(v * float64(time.Second)), v > 0
+	case time.Time:
+		d := time.Until(v)
+		return d, d > 0
 	}
 
-	return false
+	s := strings.TrimSpace(fmt.Sprintf("%v", val))
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), secs > 0
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, d > 0
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		d := time.Until(t)
+		return d, d > 0
+	}
+	return 0, false
 }
 
-func calculateDelay(config RetryConfig, attempt int) time.Duration {
+// calculateDelay returns the wait before retry number attempt+1; prev is
+// the previous delay, used by decorrelated jitter
+func calculateDelay(config RetryConfig, attempt int, prev time.Duration) time.Duration {
 	// Exponential backoff: delay = initial * multiplier^attempt
 	delay := float64(config.InitialDelay) * math.Pow(config.Multiplier, float64(attempt))
 
 	// Cap at max delay
-	if delay > float64(config.MaxDelay) {
-		delay = float64(config.MaxDelay)
+	maxDelay := float64(config.MaxDelay)
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
 	}
 
-	// Add jitter if enabled
-	if config.Jitter {
-		// Add ±10% jitter
-		jitter := 0.1 * delay
-		delay = delay - jitter/2 + (float64(time.Now().UnixNano()) * jitter / 1e9)
+	strategy := config.JitterStrategy
+	if strategy == "" {
+		strategy = JitterNone
+		if config.Jitter {
+			strategy = JitterFull
+		}
+	}
+
+	switch strategy {
+	case JitterFull:
+		delay = rand.Float64() * delay
+	case JitterEqual:
+		delay = delay/2 + rand.Float64()*delay/2
+	case JitterDecorrelated:
+		lower := float64(config.InitialDelay)
+		upper := math.Max(lower, float64(prev)*3)
+		delay = lower + rand.Float64()*(upper-lower)
+		if maxDelay > 0 && delay > maxDelay {
+			delay = maxDelay
+		}
 	}
 
 	return time.Duration(delay)
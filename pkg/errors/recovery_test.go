@@ -0,0 +1,205 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCalculateDelayBackoff(t *testing.T) {
+	config := RetryConfig{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for attempt, ms := range want {
+		if got := calculateDelay(config, attempt, 0); got != ms*time.Millisecond {
+			t.Errorf("attempt %d: delay = %v, want %v", attempt, got, ms*time.Millisecond)
+		}
+	}
+}
+
+func TestCalculateDelayJitter(t *testing.T) {
+	base := RetryConfig{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+
+	tests := []struct {
+		strategy JitterStrategy
+		jitter   bool
+		attempt  int
+		prev     time.Duration
+		min, max time.Duration
+	}{
+		{JitterNone, true, 2, 0, 400 * time.Millisecond, 400 * time.Millisecond},
+		{"", false, 2, 0, 400 * time.Millisecond, 400 * time.Millisecond},
+		{"", true, 2, 0, 0, 400 * time.Millisecond},
+		{JitterFull, false, 2, 0, 0, 400 * time.Millisecond},
+		{JitterEqual, false, 2, 0, 200 * time.Millisecond, 400 * time.Millisecond},
+		{JitterDecorrelated, false, 2, 150 * time.Millisecond, 100 * time.Millisecond, 450 * time.Millisecond},
+		{JitterDecorrelated, false, 2, 0, 100 * time.Millisecond, 100 * time.Millisecond},
+		{JitterDecorrelated, false, 5, 900 * time.Millisecond, 100 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%v/%v", tt.strategy, tt.jitter, tt.prev), func(t *testing.T) {
+			config := base
+			config.JitterStrategy = tt.strategy
+			config.Jitter = tt.jitter
+
+			for i := 0; i < 200; i++ {
+				got := calculateDelay(config, tt.attempt, tt.prev)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("delay = %v, want between %v and %v", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnNonRetryableError(t *testing.T) {
+	config := RetryConfig{MaxAttempts: 5, RetryableErrors: []ErrorCode{CodeServiceUnavailable}}
+
+	calls := 0
+	notFound := New(CodeNotFound, "missing")
+	err := Retry(context.Background(), config, func() error {
+		calls++
+		return notFound
+	})
+	if calls != 1 {
+		t.Errorf("fn called %d times, want 1", calls)
+	}
+	if err != notFound {
+		t.Errorf("err = %v, want the original error", err)
+	}
+}
+
+func TestRetrySucceedsAfterFailures(t *testing.T) {
+	config := RetryConfig{MaxAttempts: 5, InitialDelay: time.Millisecond, Multiplier: 1}
+
+	var attempts []int
+	err := RetryWithContext(context.Background(), config, func(_ context.Context, attempt int) error {
+		attempts = append(attempts, attempt)
+		if attempt < 3 {
+			return New(CodeServiceUnavailable, "busy")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	if fmt.Sprint(attempts) != "[1 2 3]" {
+		t.Errorf("attempts = %v, want [1 2 3]", attempts)
+	}
+}
+
+func TestRetryExhaustsAttempts(t *testing.T) {
+	config := RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, Multiplier: 1}
+
+	calls := 0
+	err := Retry(context.Background(), config, func() error {
+		calls++
+		return New(CodeTimeout, "slow")
+	})
+	if calls != 3 {
+		t.Errorf("fn called %d times, want 3", calls)
+	}
+	if !Is(err, CodeTimeout) {
+		t.Errorf("err = %v, want the code of the last error", err)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	budget := NewRetryBudget(2, 0)
+	config := RetryConfig{MaxAttempts: 10, InitialDelay: time.Millisecond, Multiplier: 1, Budget: budget}
+
+	calls := 0
+	err := Retry(context.Background(), config, func() error {
+		calls++
+		return New(CodeServiceUnavailable, "busy")
+	})
+	if calls != 3 {
+		t.Errorf("fn called %d times, want 1 attempt and 2 budgeted retries", calls)
+	}
+	if err == nil || budget.Available() != 0 {
+		t.Errorf("err = %v, available = %d; want an error and an empty budget", err, budget.Available())
+	}
+
+	calls = 0
+	Retry(context.Background(), config, func() error {
+		calls++
+		return New(CodeServiceUnavailable, "busy")
+	})
+	if calls != 1 {
+		t.Errorf("fn called %d times with an empty budget, want 1", calls)
+	}
+}
+
+func TestRetryBudgetRefills(t *testing.T) {
+	budget := NewRetryBudget(2, 10)
+	budget.Withdraw()
+	budget.Withdraw()
+	if budget.Withdraw() {
+		t.Fatal("withdrew from an empty budget")
+	}
+
+	budget.last = budget.last.Add(-150 * time.Millisecond)
+	if got := budget.Available(); got != 1 {
+		t.Errorf("available after 150ms at 10/s = %d, want 1", got)
+	}
+
+	budget.last = budget.last.Add(-time.Hour)
+	if got := budget.Available(); got != 2 {
+		t.Errorf("available after an hour = %d, want the bucket size 2", got)
+	}
+}
+
+func TestRetryAfterHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		hint     interface{}
+		maxDelay time.Duration
+		want     time.Duration
+	}{
+		{"seconds", 0.2, 0, 200 * time.Millisecond},
+		{"duration string", "150ms", 0, 150 * time.Millisecond},
+		{"below backoff", time.Nanosecond, 0, 10 * time.Millisecond},
+		{"capped at max delay", 3600, 50 * time.Millisecond, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := RetryConfig{
+				MaxAttempts:  2,
+				InitialDelay: 10 * time.Millisecond,
+				MaxDelay:     tt.maxDelay,
+				Multiplier:   1,
+			}
+
+			var delay time.Duration
+			config.OnRetry = func(_ int, _ error, d time.Duration) { delay = d }
+
+			RetryWithContext(context.Background(), config, func(_ context.Context, attempt int) error {
+				if attempt > 1 {
+					return nil
+				}
+				return AddMetadata(New(CodeServiceUnavailable, "busy"), MetadataRetryAfter, tt.hint)
+			})
+
+			if delay != tt.want {
+				t.Errorf("delay = %v, want %v", delay, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryGivesUpBeforeDeadline(t *testing.T) {
+	config := RetryConfig{MaxAttempts: 3, InitialDelay: time.Hour, Multiplier: 1}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := Retry(ctx, config, func() error { return New(CodeServiceUnavailable, "busy") })
+	if !Is(err, CodeTimeout) {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("waited although the delay exceeded the deadline")
+	}
+}
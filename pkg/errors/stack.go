@@ -0,0 +1,183 @@
+package errors
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Frame is a single stack frame where an error was created
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func (f Frame) String() string {
+	return fmt.Sprintf("%s\n\t%s:%d", f.Function, f.File, f.Line)
+}
+
+// StackTraceConfig controls stack capture in newly created errors.
+// The origin frame is always recorded so errors can be fingerprinted.
+type StackTraceConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	Depth   int  `json:"depth" yaml:"depth"`
+}
+
+const defaultStackDepth = 32
+
+var (
+	stackEnabled atomic.Bool
+	stackDepth   atomic.Int32
+)
+
+func init() {
+	SetStackTraceConfig(StackTraceConfig{Enabled: true, Depth: defaultStackDepth})
+}
+
+// SetStackTraceConfig changes stack capture for errors created afterwards
+func SetStackTraceConfig(cfg StackTraceConfig) {
+	if cfg.Depth <= 0 {
+		cfg.Depth = defaultStackDepth
+	}
+	stackEnabled.Store(cfg.Enabled)
+	stackDepth.Store(int32(cfg.Depth))
+}
+
+// StackTraceConfigFor returns the stack capture settings for an environment:
+// full stacks in development and test, origin only in production
+func StackTraceConfigFor(environment string) StackTraceConfig {
+	switch strings.ToLower(environment) {
+	case "production", "prod":
+		return StackTraceConfig{Enabled: false, Depth: defaultStackDepth}
+	default:
+		return StackTraceConfig{Enabled: true, Depth: defaultStackDepth}
+	}
+}
+
+// captureStack records the caller's stack, skipping skip frames above
+// captureStack itself
+func captureStack(skip int) []Frame {
+	depth := 1
+	if stackEnabled.Load() {
+		depth = int(stackDepth.Load())
+	}
+
+	pc := make([]uintptr, depth)
+	n := runtime.Callers(skip+2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	stack := make([]Frame, 0, n)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+func formatStack(frames []Frame) string {
+	var b strings.Builder
+	for _, frame := range frames {
+		b.WriteString(frame.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// StackFrames returns the captured stack, innermost frame first.
+// Only the origin is available when stack capture is disabled.
+func (e *Error) StackFrames() []Frame {
+	frames := make([]Frame, len(e.frames))
+	copy(frames, e.frames)
+	return frames
+}
+
+// Origin returns the frame where the error was created
+func (e *Error) Origin() Frame {
+	if len(e.frames) == 0 {
+		return Frame{}
+	}
+	return e.frames[0]
+}
+
+// Fingerprint is a stable hash of the error code and origin function,
+// including the root *Error cause, for grouping recurring failures.
+// Line numbers are left out so edits elsewhere in a file keep the group.
+func (e *Error) Fingerprint() string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s|%s", e.Code, e.Origin().Function)
+
+	if root := rootError(e); root != e {
+		fmt.Fprintf(h, "|%s|%s", root.Code, root.Origin().Function)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Format implements fmt.Formatter. %+v prints the whole cause chain with
+// metadata and stack frames; %v, %s and %q print Error(). Other verbs are
+// reported the way fmt reports a bad verb, e.g. %!d(*errors.Error=...).
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			e.writeDetailed(s)
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(*errors.Error=%s)", verb, e.Error())
+	}
+}
+
+func (e *Error) writeDetailed(w io.Writer) {
+	fmt.Fprintf(w, "%s: %s", e.Code, e.Message)
+
+	if len(e.Metadata) > 0 {
+		keys := make([]string, 0, len(e.Metadata))
+		for k := range e.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		io.WriteString(w, "\n    metadata:")
+		for _, k := range keys {
+			fmt.Fprintf(w, " %s=%v", k, e.Metadata[k])
+		}
+	}
+
+	for _, frame := range e.frames {
+		fmt.Fprintf(w, "\n    %s\n        %s:%d", frame.Function, frame.File, frame.Line)
+	}
+
+	if e.Cause != nil {
+		fmt.Fprintf(w, "\ncaused by: %+v", e.Cause)
+	}
+}
+
+// rootError returns the innermost *Error in the cause chain
+func rootError(e *Error) *Error {
+	for {
+		cause, ok := e.Cause.(*Error)
+		if !ok {
+			return e
+		}
+		e = cause
+	}
+}
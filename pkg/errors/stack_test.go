@@ -0,0 +1,121 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func setStackTrace(t *testing.T, cfg StackTraceConfig) {
+	t.Helper()
+	SetStackTraceConfig(cfg)
+	t.Cleanup(func() { SetStackTraceConfig(StackTraceConfig{Enabled: true}) })
+}
+
+func lookupFailed() *Error { return New(CodeNotFound, "lookup failed") }
+func storeFailed() *Error  { return New(CodeNotFound, "store failed") }
+
+func TestOrigin(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+	}{
+		{"New", New(CodeInternalError, "x")},
+		{"Newf", Newf(CodeInternalError, "%d", 1)},
+		{"Wrap", Wrap(fmt.Errorf("x"), CodeInternalError, "x")},
+		{"ValidationError", ValidationError("name", "is required")},
+		{"NotFoundError", NotFoundError("service", "api")},
+	}
+	for _, tt := range tests {
+		origin := tt.err.Origin()
+		if !strings.HasSuffix(origin.Function, ".TestOrigin") || !strings.HasSuffix(origin.File, "stack_test.go") {
+			t.Errorf("%s: origin = %s, want the calling test", tt.name, origin)
+		}
+	}
+}
+
+func TestStackCapture(t *testing.T) {
+	setStackTrace(t, StackTraceConfig{Enabled: true, Depth: 2})
+	if n := len(lookupFailed().StackFrames()); n != 2 {
+		t.Errorf("%d frames with depth 2", n)
+	}
+
+	setStackTrace(t, StackTraceConfig{Enabled: false})
+	frames := lookupFailed().StackFrames()
+	if len(frames) != 1 || !strings.HasSuffix(frames[0].Function, ".lookupFailed") {
+		t.Errorf("frames = %v, want only the origin when capture is disabled", frames)
+	}
+
+	if cfg := StackTraceConfigFor("Production"); cfg.Enabled {
+		t.Error("stacks enabled in production")
+	}
+	if cfg := StackTraceConfigFor("dev"); !cfg.Enabled {
+		t.Error("stacks disabled in development")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := lookupFailed()
+	if b := lookupFailed(); a.Fingerprint() != b.Fingerprint() {
+		t.Error("same code and origin give different fingerprints")
+	}
+	if len(a.Fingerprint()) != 16 {
+		t.Errorf("fingerprint %q is not 16 hex digits", a.Fingerprint())
+	}
+
+	// the message and line do not matter, the function and code do
+	first := New(CodeNotFound, "first")
+	second := New(CodeNotFound, "second")
+	if first.Origin().Line == second.Origin().Line || first.Fingerprint() != second.Fingerprint() {
+		t.Error("errors from different lines of one function give different fingerprints")
+	}
+	if storeFailed().Fingerprint() == a.Fingerprint() {
+		t.Error("different origins give the same fingerprint")
+	}
+	other := lookupFailed()
+	other.Code = CodeTimeout
+	if other.Fingerprint() == a.Fingerprint() {
+		t.Error("different codes give the same fingerprint")
+	}
+
+	// wrapping errors from different roots keeps them apart
+	wrap := func(cause error) *Error { return Wrap(cause, CodeServiceUnavailable, "registry failed") }
+	if wrap(lookupFailed()).Fingerprint() == wrap(storeFailed()).Fingerprint() {
+		t.Error("root cause left out of the fingerprint")
+	}
+	if wrap(lookupFailed()).Fingerprint() != wrap(lookupFailed()).Fingerprint() {
+		t.Error("same root cause gives different fingerprints")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	err := AddMetadata(Wrap(lookupFailed(), CodeServiceUnavailable, "registry failed"), "zone", "eu")
+
+	tests := []struct {
+		format, want string
+	}{
+		{"%v", err.Error()},
+		{"%s", err.Error()},
+		{"%q", fmt.Sprintf("%q", err.Error())},
+		{"%d", "%!d(*errors.Error=" + err.Error() + ")"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, err); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	detailed := fmt.Sprintf("%+v", err)
+	for _, want := range []string{
+		"SERVICE_UNAVAILABLE: registry failed",
+		"metadata: zone=eu",
+		".TestFormat\n",
+		"caused by: NOT_FOUND: lookup failed",
+		".lookupFailed\n",
+		"stack_test.go:",
+	} {
+		if !strings.Contains(detailed, want) {
+			t.Errorf("%%+v missing %q:\n%s", want, detailed)
+		}
+	}
+}
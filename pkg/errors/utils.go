@@ -12,9 +12,21 @@ func Is(err error, codes ...ErrorCode) bool {
 		return false
 	}
 
+	if multi, ok := err.(*MultiError); ok {
+		for _, e := range multi.Errors {
+			if Is(e, codes...) {
+				return true
+			}
+		}
+		return false
+	}
+
 	var appErr *Error
 	if e, ok := err.(*Error); ok {
 		appErr = e
+	} else if e, ok := fromStatusError(err); ok {
+		// Error returned by a gRPC call
+		appErr = e
 	} else {
 		// Check if wrapped
 		if unwrapped := errors.Unwrap(err); unwrapped != nil {
@@ -48,6 +60,16 @@ func As(err error, target *ErrorCode) bool {
 		return true
 	}
 
+	if multi, ok := err.(*MultiError); ok {
+		*target = multi.Code()
+		return true
+	}
+
+	if appErr, ok := fromStatusError(err); ok {
+		*target = appErr.Code
+		return true
+	}
+
 	// Check cause chain
 	if unwrapped := errors.Unwrap(err); unwrapped != nil {
 		return As(unwrapped, target)
@@ -56,63 +78,59 @@ func As(err error, target *ErrorCode) bool {
 	return false
 }
 
-// Combine combines multiple errors into one
+// Combine combines multiple errors into one. Nil errors are skipped; a
+// single error is returned as is and several as a *MultiError.
 func Combine(errs ...error) error {
-	var nonNilErrs []error
-	for _, err := range errs {
-		if err != nil {
-			nonNilErrs = append(nonNilErrs, err)
-		}
-	}
-
-	if len(nonNilErrs) == 0 {
-		return nil
-	}
+	multi := &MultiError{}
+	multi.Append(errs...)
 
-	if len(nonNilErrs) == 1 {
-		return nonNilErrs[0]
+	if len(multi.Errors) == 1 {
+		return multi.Errors[0]
 	}
-
-	// Create combined error
-	combinedMsg := "multiple errors occurred:"
-	for i, err := range nonNilErrs {
-		combinedMsg += fmt.Sprintf("\n  %d. %v", i+1, err)
-	}
-
-	return New(CodeInternalError, combinedMsg)
+	return multi.ErrorOrNil()
 }
 
 // ValidationError creates a validation error with field details
 func ValidationError(field, message string) *Error {
-	return New(CodeValidation, fmt.Sprintf("validation failed for field '%s': %s", field, message))
+	return newError(CodeValidation,
+		fmt.Sprintf("validation failed for field '%s': %s", field, message), nil,
+		map[string]interface{}{"field": field, "reason": message})
 }
 
 // NotFoundError creates a not found error
 func NotFoundError(resourceType, identifier string) *Error {
-	return New(CodeNotFound,
-		fmt.Sprintf("%s '%s' not found", resourceType, identifier))
+	return newError(CodeNotFound,
+		fmt.Sprintf("%s '%s' not found", resourceType, identifier), nil,
+		map[string]interface{}{"resource": resourceType, "id": identifier})
 }
 
 // AlreadyExistsError creates an already exists error
 func AlreadyExistsError(resourceType, identifier string) *Error {
-	return New(CodeAlreadyExists,
-		fmt.Sprintf("%s '%s' already exists", resourceType, identifier))
+	return newError(CodeAlreadyExists,
+		fmt.Sprintf("%s '%s' already exists", resourceType, identifier), nil,
+		map[string]interface{}{"resource": resourceType, "id": identifier})
 }
 
 // UnauthorizedError creates an unauthorized error
 func UnauthorizedError(reason string) *Error {
-	return New(CodeUnauthorized,
-		fmt.Sprintf("unauthorized: %s", reason))
+	return newError(CodeUnauthorized,
+		fmt.Sprintf("unauthorized: %s", reason), nil,
+		map[string]interface{}{"reason": reason})
 }
 
 // TimeoutError creates a timeout error
 func TimeoutError(operation string, duration time.Duration) *Error {
-	return New(CodeTimeout,
-		fmt.Sprintf("operation '%s' timed out after %v", operation, duration))
+	return newError(CodeTimeout,
+		fmt.Sprintf("operation '%s' timed out after %v", operation, duration), nil,
+		map[string]interface{}{"operation": operation, "timeout": duration.String()})
 }
 
 // NetworkError creates a network error
 func NetworkError(operation, endpoint string, cause error) *Error {
-	return Wrap(cause, CodeNetworkError,
-		fmt.Sprintf("network error during %s to %s", operation, endpoint))
+	if cause == nil {
+		return nil
+	}
+	return newError(CodeNetworkError,
+		fmt.Sprintf("network error during %s to %s", operation, endpoint), cause,
+		map[string]interface{}{"operation": operation, "endpoint": endpoint})
 }
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"fmt"
+	"time"
+)
+
+// AppConfig holds the application's logging settings as plain values, with
+// levels and formats given by name. The config package converts its
+// logging section to it, so the logger does not depend on the schema.
+type AppConfig struct {
+	Level  string
+	Format string // json or text
+	Output string // stdout, stderr, or file path
+
+	// file rotation, in MB, files and days
+	MaxSize    int
+	MaxBackups int
+	MaxAge     int
+
+	// several simultaneous outputs; when set, Format and Output are ignored
+	Outputs []OutputConfig
+
+	// volume control, see Config; DedupLevel defaults to error
+	Sampling    SamplingConfig
+	RateLimit   RateLimitConfig
+	DedupWindow time.Duration
+	DedupLevel  string
+
+	// secrets hidden before entries reach any output
+	Redaction RedactionConfig
+}
+
+// OutputConfig is one destination of log entries with its own format and
+// level. Level can only raise the minimum set by AppConfig.Level.
+type OutputConfig struct {
+	Output string // stdout, stderr, or file path
+	Format string // json or text, default json
+	Level  string // default: every entry the logger accepts
+
+	// file rotation; zero values fall back to the top-level settings
+	MaxSize    int
+	MaxBackups int
+	MaxAge     int
+	Compress   bool
+}
+
+// FromAppConfig creates a logger from the application logging section
+func FromAppConfig(cfg AppConfig) (Logger, error) {
+	config, err := ConfigFromApp(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return New(config)
+}
+
+// ConfigFromApp converts the application logging section to a Config.
+// Unknown levels are reported with the key they came from.
+func ConfigFromApp(cfg AppConfig) (Config, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return Config{}, fmt.Errorf("logging.level: %w", err)
+	}
+
+	config := Config{
+		Level:        level,
+		Encoding:     encodingFromFormat(cfg.Format),
+		OutputPath:   cfg.Output,
+		EnableCaller: true,
+		MaxSize:      cfg.MaxSize,
+		MaxBackups:   cfg.MaxBackups,
+		MaxAge:       cfg.MaxAge,
+		Sampling:     cfg.Sampling,
+		RateLimit:    cfg.RateLimit,
+		Redaction:    cfg.Redaction,
+	}
+
+	if cfg.DedupWindow > 0 {
+		dedupLevel := ErrorLevel
+		if cfg.DedupLevel != "" {
+			if dedupLevel, err = ParseLevel(cfg.DedupLevel); err != nil {
+				return Config{}, fmt.Errorf("logging.dedup.level: %w", err)
+			}
+		}
+		config.Dedup = DedupConfig{Window: cfg.DedupWindow, Level: dedupLevel}
+	}
+
+	for i, out := range cfg.Outputs {
+		sinkLevel := DebugLevel
+		if out.Level != "" {
+			if sinkLevel, err = ParseLevel(out.Level); err != nil {
+				return Config{}, fmt.Errorf("logging.outputs[%d].level: %w", i, err)
+			}
+		}
+
+		sink := SinkConfig{
+			Level:      sinkLevel,
+			Encoding:   encodingFromFormat(out.Format),
+			OutputPath: out.Output,
+			MaxSize:    out.MaxSize,
+			MaxBackups: out.MaxBackups,
+			MaxAge:     out.MaxAge,
+			Compress:   out.Compress,
+		}
+		if sink.MaxSize == 0 {
+			sink.MaxSize = cfg.MaxSize
+		}
+		if sink.MaxBackups == 0 {
+			sink.MaxBackups = cfg.MaxBackups
+		}
+		if sink.MaxAge == 0 {
+			sink.MaxAge = cfg.MaxAge
+		}
+		config.Sinks = append(config.Sinks, sink)
+	}
+
+	return config, nil
+}
+
+func encodingFromFormat(format string) string {
+	if format == "console" || format == "text" {
+		return "console"
+	}
+	return "json"
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"context"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	traceKey
+	tenantKey
+	scenarioKey
+)
+
+// field names added by WithContext
+const (
+	FieldKeyRequestID = "request_id"
+	FieldKeyTraceID   = "trace_id"
+	FieldKeySpanID    = "span_id"
+	FieldKeyTenant    = "tenant"
+	FieldKeyScenario  = "scenario_id"
+)
+
+type traceIDs struct {
+	traceID string
+	spanID  string
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID or ""
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// ContextWithTrace returns a copy of ctx carrying trace and span IDs
+func ContextWithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, traceKey, traceIDs{traceID: traceID, spanID: spanID})
+}
+
+// TraceFromContext returns the trace and span IDs or empty strings
+func TraceFromContext(ctx context.Context) (traceID, spanID string) {
+	ids, _ := ctx.Value(traceKey).(traceIDs)
+	return ids.traceID, ids.spanID
+}
+
+// ContextWithTenant returns a copy of ctx carrying the tenant
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// TenantFromContext returns the tenant or ""
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey).(string)
+	return tenant
+}
+
+// ContextWithScenario returns a copy of ctx carrying the mock scenario ID
+func ContextWithScenario(ctx context.Context, scenarioID string) context.Context {
+	return context.WithValue(ctx, scenarioKey, scenarioID)
+}
+
+// ScenarioFromContext returns the mock scenario ID or ""
+func ScenarioFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(scenarioKey).(string)
+	return id
+}
+
+// ContextFields returns the correlation IDs stored in ctx as fields,
+// leaving out the ones that are not set
+func ContextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields []Field
+	add := func(key, value string) {
+		if value != "" {
+			fields = append(fields, FieldString(key, value))
+		}
+	}
+
+	add(FieldKeyRequestID, RequestIDFromContext(ctx))
+	traceID, spanID := TraceFromContext(ctx)
+	add(FieldKeyTraceID, traceID)
+	add(FieldKeySpanID, spanID)
+	add(FieldKeyTenant, TenantFromContext(ctx))
+	add(FieldKeyScenario, ScenarioFromContext(ctx))
+
+	return fields
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextFields(t *testing.T) {
+	ctx := context.Background()
+	if fields := ContextFields(ctx); len(fields) != 0 {
+		t.Errorf("empty context gave %v", fields)
+	}
+	if fields := ContextFields(nil); len(fields) != 0 {
+		t.Errorf("nil context gave %v", fields)
+	}
+
+	ctx = ContextWithScenario(ctx, "slow-db")
+	ctx = ContextWithTenant(ctx, "acme")
+	ctx = ContextWithTrace(ctx, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	ctx = ContextWithRequestID(ctx, "req-1")
+
+	want := []Field{
+		FieldString(FieldKeyRequestID, "req-1"),
+		FieldString(FieldKeyTraceID, "4bf92f3577b34da6a3ce929d0e0e4736"),
+		FieldString(FieldKeySpanID, "00f067aa0ba902b7"),
+		FieldString(FieldKeyTenant, "acme"),
+		FieldString(FieldKeyScenario, "slow-db"),
+	}
+	got := ContextFields(ctx)
+	if len(got) != len(want) {
+		t.Fatalf("fields = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("field %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// only the IDs that are set become fields
+	partial := ContextFields(ContextWithTenant(context.Background(), "acme"))
+	if len(partial) != 1 || partial[0] != FieldString(FieldKeyTenant, "acme") {
+		t.Errorf("tenant only: fields = %v", partial)
+	}
+}
+
+func TestZapLoggerWithContext(t *testing.T) {
+	z, logs := newObservedLogger(DebugLevel)
+
+	if l := z.WithContext(context.Background()); l != z {
+		t.Error("WithContext without IDs should return the same logger")
+	}
+
+	ctx := ContextWithTenant(ContextWithRequestID(context.Background(), "req-1"), "acme")
+	z.WithContext(ctx).Info("handled", FieldInt("status", 200))
+	z.Info("no context")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields[FieldKeyRequestID] != "req-1" || fields[FieldKeyTenant] != "acme" || fields["status"] != int64(200) {
+		t.Errorf("fields = %v, want request_id, tenant and status", fields)
+	}
+	if _, ok := entries[1].ContextMap()[FieldKeyRequestID]; ok {
+		t.Error("correlation fields leaked into the parent logger")
+	}
+}
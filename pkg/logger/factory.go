@@ -11,9 +11,22 @@ var (
 	loggerOnce    sync.Once
 )
 
-// creates a new logger based on config
+// creates a new logger based on config, wrapped in a RedactingLogger if
+// redaction is enabled
 func New(config Config) (Logger, error) {
-	return NewZapLogger(config)
+	zl, err := NewZapLogger(config)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Redaction.Enabled {
+		return zl, nil
+	}
+
+	redactor, err := NewRedactor(config.Redaction)
+	if err != nil {
+		return nil, err
+	}
+	return Redact(zl, redactor), nil
 }
 
 func Default() Logger {
@@ -31,6 +44,8 @@ func Default() Logger {
 
 // setDefault sets the default logger
 func SetDefault(logger Logger) {
+	// mark the lazy default as done so Default() does not replace this one
+	loggerOnce.Do(func() {})
 	defaultLogger = logger
 }
 
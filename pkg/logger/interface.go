@@ -73,6 +73,31 @@ type Config struct {
 	MaxBackups int  `json:"max_backups" yaml:"max_backups"`
 	MaxAge     int  `json:"max_age" yaml:"max_age"` // days
 	Compress   bool `json:"compress" yaml:"compress"`
+
+	// several outputs at once; when set, Encoding, OutputPath and the
+	// file settings above are ignored
+	Sinks []SinkConfig `json:"sinks" yaml:"sinks"`
+
+	// volume control for busy loggers; zero values disable each one
+	Sampling  SamplingConfig  `json:"sampling" yaml:"sampling"`
+	RateLimit RateLimitConfig `json:"rate_limit" yaml:"rate_limit"`
+	Dedup     DedupConfig     `json:"dedup" yaml:"dedup"`
+
+	// hide secrets in messages and field values
+	Redaction RedactionConfig `json:"redaction" yaml:"redaction"`
+}
+
+// SinkConfig is one output of a logger. Level filters entries after the
+// logger's own level, so it can only raise the minimum.
+type SinkConfig struct {
+	Level      Level  `json:"level" yaml:"level"`
+	Encoding   string `json:"encoding" yaml:"encoding"`
+	OutputPath string `json:"output_path" yaml:"output_path"`
+
+	MaxSize    int  `json:"max_size" yaml:"max_size"`
+	MaxBackups int  `json:"max_backups" yaml:"max_backups"`
+	MaxAge     int  `json:"max_age" yaml:"max_age"`
+	Compress   bool `json:"compress" yaml:"compress"`
 }
 
 func FieldTime(key string, value time.Time) Field {
@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ParseLevel parses a level name as used in configuration files
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return DebugLevel, nil
+	case "info", "":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	case "panic":
+		return PanicLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("unknown log level '%s'", s)
+	}
+}
+
+// LevelRegistry tracks named loggers so their levels can be read and
+// changed at runtime, e.g. through Handler
+type LevelRegistry struct {
+	mu      sync.RWMutex
+	loggers map[string]Logger
+}
+
+// DefaultLevels holds the loggers registered by Named
+var DefaultLevels = NewLevelRegistry()
+
+func NewLevelRegistry() *LevelRegistry {
+	return &LevelRegistry{loggers: make(map[string]Logger)}
+}
+
+// Register adds or replaces the logger called name
+func (r *LevelRegistry) Register(name string, l Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.loggers[name] = l
+}
+
+// Lookup returns the logger called name
+func (r *LevelRegistry) Lookup(name string) (Logger, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	l, ok := r.loggers[name]
+	return l, ok
+}
+
+// Levels returns the current level of every registered logger
+func (r *LevelRegistry) Levels() map[string]Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	levels := make(map[string]Level, len(r.loggers))
+	for name, l := range r.loggers {
+		levels[name] = l.GetLevel()
+	}
+	return levels
+}
+
+// SetLevel changes the level of the logger called name
+func (r *LevelRegistry) SetLevel(name string, level Level) error {
+	l, ok := r.Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown logger '%s'", name)
+	}
+	l.SetLevel(level)
+	return nil
+}
+
+// SetAll changes the level of every registered logger
+func (r *LevelRegistry) SetAll(level Level) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, l := range r.loggers {
+		l.SetLevel(level)
+	}
+}
+
+// Named returns a child of l called name and registers it in DefaultLevels.
+// Loggers that cannot create named children get a "logger" field instead
+// and share l's level.
+func Named(l Logger, name string) Logger {
+	var child Logger
+	if n, ok := l.(interface{ Named(string) Logger }); ok {
+		child = n.Named(name)
+	} else {
+		child = l.With(FieldString("logger", name))
+	}
+
+	DefaultLevels.Register(name, child)
+	return child
+}
+
+type loggerLevel struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
+
+// Handler serves the registered loggers and their levels:
+//
+//	GET                     list every logger
+//	GET  ?name=registry     one logger
+//	PUT  ?name=registry     change one logger, body {"level": "debug"}
+//	PUT                     change every logger
+//
+// The level may also be given as ?level=debug. POST is accepted as PUT.
+func (r *LevelRegistry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name := req.URL.Query().Get("name")
+
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			levelName := req.URL.Query().Get("level")
+			if levelName == "" {
+				var body struct {
+					Level string `json:"level"`
+				}
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+					writeLevelError(w, http.StatusBadRequest, "body must be {\"level\": \"<level>\"}")
+					return
+				}
+				levelName = body.Level
+			}
+
+			level, err := ParseLevel(levelName)
+			if err != nil || levelName == "" {
+				writeLevelError(w, http.StatusBadRequest, fmt.Sprintf("unknown log level '%s'", levelName))
+				return
+			}
+
+			if name == "" {
+				r.SetAll(level)
+			} else if err := r.SetLevel(name, level); err != nil {
+				writeLevelError(w, http.StatusNotFound, err.Error())
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeLevelError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		if name != "" {
+			l, ok := r.Lookup(name)
+			if !ok {
+				writeLevelError(w, http.StatusNotFound, fmt.Sprintf("unknown logger '%s'", name))
+				return
+			}
+			writeLevelJSON(w, http.StatusOK, loggerLevel{Name: name, Level: l.GetLevel().String()})
+			return
+		}
+
+		levels := r.Levels()
+		list := make([]loggerLevel, 0, len(levels))
+		for n, level := range levels {
+			list = append(list, loggerLevel{Name: n, Level: level.String()})
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+		writeLevelJSON(w, http.StatusOK, map[string]interface{}{"loggers": list})
+	})
+}
+
+func writeLevelError(w http.ResponseWriter, status int, message string) {
+	writeLevelJSON(w, status, map[string]string{"error": message})
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
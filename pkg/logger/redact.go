@@ -0,0 +1,293 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+const defaultRedactionText = "[REDACTED]"
+
+// keys and value patterns always redacted when redaction is enabled
+var (
+	DefaultRedactKeys = []string{
+		"authorization", "proxy-authorization", "cookie", "set-cookie",
+		"*password*", "*passwd*", "*secret*", "*token*",
+		"api_key", "apikey", "x-api-key", "private_key",
+	}
+	DefaultRedactPatterns = []string{
+		`(?i)\bbearer\s+[a-z0-9\-._~+/]+=*`,
+		`(?i)\bbasic\s+[a-z0-9+/]+=*`,
+		`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`, // JWT
+	}
+)
+
+// RedactionConfig selects the field values hidden by the redaction layer.
+// Keys and Patterns are added to DefaultRedactKeys and DefaultRedactPatterns.
+type RedactionConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// case-insensitive glob patterns matched against the field key and
+	// against its last dotted segment, e.g. "*password*" or "authorization"
+	Keys []string `json:"keys" yaml:"keys"`
+
+	// regular expressions whose matches are replaced inside string values,
+	// errors and the message itself
+	Patterns []string `json:"patterns" yaml:"patterns"`
+
+	// text written in place of a secret, default [REDACTED]
+	Replacement string `json:"replacement" yaml:"replacement"`
+}
+
+// Redacted is a string that is never written to logs, whatever the
+// logger or encoder
+type Redacted string
+
+func (Redacted) String() string               { return defaultRedactionText }
+func (Redacted) GoString() string             { return defaultRedactionText }
+func (Redacted) Format(s fmt.State, _ rune)   { fmt.Fprint(s, defaultRedactionText) }
+func (Redacted) MarshalText() ([]byte, error) { return []byte(defaultRedactionText), nil }
+func (Redacted) MarshalJSON() ([]byte, error) { return []byte(`"` + defaultRedactionText + `"`), nil }
+func (Redacted) LogValue() slog.Value         { return slog.StringValue(defaultRedactionText) }
+
+// FieldRedacted adds a field whose value is always written as [REDACTED]
+func FieldRedacted(key string, value string) Field {
+	return Field{Key: key, Value: Redacted(value)}
+}
+
+// Redactor rewrites fields so that secrets are replaced
+type Redactor struct {
+	keys        []string
+	patterns    []*regexp.Regexp
+	replacement string
+}
+
+// NewRedactor compiles cfg; invalid key globs or regexes are errors
+func NewRedactor(cfg RedactionConfig) (*Redactor, error) {
+	r := &Redactor{replacement: cfg.Replacement}
+	if r.replacement == "" {
+		r.replacement = defaultRedactionText
+	}
+
+	for _, key := range append(append([]string{}, DefaultRedactKeys...), cfg.Keys...) {
+		key = strings.ToLower(key)
+		if _, err := path.Match(key, ""); err != nil {
+			return nil, fmt.Errorf("invalid redaction key pattern '%s': %w", key, err)
+		}
+		r.keys = append(r.keys, key)
+	}
+
+	for _, expr := range append(append([]string{}, DefaultRedactPatterns...), cfg.Patterns...) {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern '%s': %w", expr, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// Fields returns a copy of fields with secrets replaced
+func (r *Redactor) Fields(fields []Field) []Field {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	redacted := make([]Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = Field{Key: f.Key, Value: r.value(f.Key, f.Value)}
+	}
+	return redacted
+}
+
+// Map returns a copy of m, e.g. configuration settings, with the values of
+// secret keys replaced, nested maps included
+func (r *Redactor) Map(m map[string]interface{}) map[string]interface{} {
+	redacted, _ := r.value("", m).(map[string]interface{})
+	return redacted
+}
+
+// String replaces every pattern match in s
+func (r *Redactor) String(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, r.replacement)
+	}
+	return s
+}
+
+func (r *Redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	last := key
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		last = key[i+1:]
+	}
+
+	for _, pattern := range r.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, last); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) value(key string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if r.matchKey(key) {
+		return r.replacement
+	}
+
+	switch v := value.(type) {
+	case Redacted:
+		return r.replacement
+	case string:
+		return r.String(v)
+	case []byte:
+		return r.String(string(v))
+	case error:
+		// never pass the error on: encoders may print it with %+v, which
+		// can include metadata and causes the patterns have not seen
+		return r.String(v.Error())
+	}
+
+	// maps such as http.Header or metadata.MD are redacted by key
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		redacted := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			redacted[k] = r.value(k, iter.Value().Interface())
+		}
+		return redacted
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() != reflect.String {
+			return value
+		}
+		redacted := make([]string, rv.Len())
+		for i := range redacted {
+			redacted[i] = r.String(rv.Index(i).String())
+		}
+		return redacted
+	}
+
+	return value
+}
+
+// RedactingLogger wraps any Logger and redacts messages and fields before
+// they reach it
+type RedactingLogger struct {
+	next     Logger
+	redactor *Redactor
+}
+
+// Redact wraps next with r
+func Redact(next Logger, r *Redactor) *RedactingLogger {
+	return &RedactingLogger{next: next, redactor: r}
+}
+
+func (l *RedactingLogger) Debug(msg string, fields ...Field) {
+	l.log(DebugLevel, msg, fields)
+}
+
+func (l *RedactingLogger) Info(msg string, fields ...Field) {
+	l.log(InfoLevel, msg, fields)
+}
+
+func (l *RedactingLogger) Warn(msg string, fields ...Field) {
+	l.log(WarnLevel, msg, fields)
+}
+
+func (l *RedactingLogger) Error(msg string, fields ...Field) {
+	l.log(ErrorLevel, msg, fields)
+}
+
+func (l *RedactingLogger) Fatal(msg string, fields ...Field) {
+	l.log(FatalLevel, msg, fields)
+}
+
+func (l *RedactingLogger) Panic(msg string, fields ...Field) {
+	l.log(PanicLevel, msg, fields)
+}
+
+func (l *RedactingLogger) WithContext(ctx context.Context) Logger {
+	return Redact(l.next.WithContext(ctx), l.redactor)
+}
+
+func (l *RedactingLogger) With(fields ...Field) Logger {
+	return Redact(l.next.With(l.redactor.Fields(fields)...), l.redactor)
+}
+
+func (l *RedactingLogger) WithPrefix(prefix string) Logger {
+	return Redact(l.next.WithPrefix(prefix), l.redactor)
+}
+
+// Named keeps named children of the wrapped logger redacted
+func (l *RedactingLogger) Named(name string) Logger {
+	if n, ok := l.next.(interface{ Named(string) Logger }); ok {
+		return Redact(n.Named(name), l.redactor)
+	}
+	return l.With(FieldString("logger", name))
+}
+
+func (l *RedactingLogger) Sync() error {
+	return l.next.Sync()
+}
+
+func (l *RedactingLogger) SetLevel(level Level) {
+	l.next.SetLevel(level)
+}
+
+func (l *RedactingLogger) GetLevel() Level {
+	return l.next.GetLevel()
+}
+
+func (l *RedactingLogger) logAt(level Level, pc uintptr, msg string, fields []Field) {
+	msg, fields = l.redactor.String(msg), l.redactor.Fields(fields)
+	if cl, ok := l.next.(callerLogger); ok {
+		cl.logAt(level, pc, msg, fields)
+		return
+	}
+	logAtLevel(l.next, level, msg, fields)
+}
+
+func (l *RedactingLogger) log(level Level, msg string, fields []Field) {
+	msg, fields = l.redactor.String(msg), l.redactor.Fields(fields)
+
+	// keep the caller of the exported method as the entry's caller
+	if cl, ok := l.next.(callerLogger); ok {
+		var pcs [1]uintptr
+		runtime.Callers(3, pcs[:])
+		cl.logAt(level, pcs[0], msg, fields)
+		return
+	}
+
+	switch level {
+	case DebugLevel:
+		l.next.Debug(msg, fields...)
+	case InfoLevel:
+		l.next.Info(msg, fields...)
+	case WarnLevel:
+		l.next.Warn(msg, fields...)
+	case ErrorLevel:
+		l.next.Error(msg, fields...)
+	case FatalLevel:
+		l.next.Fatal(msg, fields...)
+	default:
+		l.next.Panic(msg, fields...)
+	}
+}
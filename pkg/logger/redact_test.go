@@ -0,0 +1,133 @@
+package logger_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/logger"
+)
+
+var secrets = []string{"hunter2", "dXNlcjpwYXNz", "s3cr3t-token", "abc.def"}
+
+func secretError() *errors.Error {
+	cause := errors.AddMetadata(errors.New(errors.CodeUnauthorized, "login rejected"),
+		"authorization", "Basic dXNlcjpwYXNz")
+	err := errors.Wrap(cause, errors.CodeInternalError, "upstream call failed")
+	return errors.AddMetadata(err, "password", "hunter2")
+}
+
+func newRedactingLogger(t *testing.T) (logger.Logger, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := logger.New(logger.Config{
+		Level:      logger.DebugLevel,
+		Encoding:   "json",
+		OutputPath: path,
+		Redaction:  logger.RedactionConfig{Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return log, path
+}
+
+func TestRedactionHidesErrorMetadata(t *testing.T) {
+	log, path := newRedactingLogger(t)
+
+	err := secretError()
+	if verbose := fmt.Sprintf("%+v", err); !strings.Contains(verbose, "hunter2") {
+		t.Fatalf("test error does not carry its secret in %%+v: %s", verbose)
+	}
+
+	log.Error("request failed",
+		logger.FieldError(err),
+		logger.FieldAny("cause", err.Cause),
+		logger.FieldAny("headers", map[string][]string{"Authorization": {"Bearer s3cr3t-token"}}),
+		logger.FieldString("note", "retried with token eyJhbGciOiJIUzI1NiJ9.abc.def"),
+		logger.FieldRedacted("api_secret", "s3cr3t-token"),
+	)
+	log.Sync()
+
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		t.Fatalf("read log: %v", readErr)
+	}
+	out := string(data)
+	if !strings.Contains(out, "upstream call failed") {
+		t.Fatalf("entry missing from output: %s", out)
+	}
+	for _, secret := range secrets {
+		if strings.Contains(out, secret) {
+			t.Errorf("secret %q written to log: %s", secret, out)
+		}
+	}
+}
+
+func TestRedactorFields(t *testing.T) {
+	r, err := logger.NewRedactor(logger.RedactionConfig{
+		Enabled:     true,
+		Keys:        []string{"*session*"},
+		Patterns:    []string{`card=\d+`},
+		Replacement: "***",
+	})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		field logger.Field
+		want  string
+	}{
+		{"key match", logger.FieldString("password", "hunter2"), "***"},
+		{"dotted key", logger.FieldString("db.Password", "hunter2"), "***"},
+		{"custom key", logger.FieldString("user_session_id", "42"), "***"},
+		{"bearer value", logger.FieldString("note", "sent Bearer abc123"), "sent ***"},
+		{"custom pattern", logger.FieldString("payment", "card=4111 ok"), "*** ok"},
+		{"redacted type", logger.FieldRedacted("anything", "hunter2"), "***"},
+		{"plain value", logger.FieldString("user", "alice"), "alice"},
+		{"error", logger.FieldError(secretError()),
+			"INTERNAL_ERROR: upstream call failed (caused by: UNAUTHORIZED: login rejected)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.Fields([]logger.Field{tt.field})[0].Value
+			if s, ok := got.(string); !ok || s != tt.want {
+				t.Errorf("got %#v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorMap(t *testing.T) {
+	r, err := logger.NewRedactor(logger.RedactionConfig{})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+
+	got := r.Map(map[string]interface{}{
+		"server": map[string]interface{}{"admin_token": "hunter2", "port": 50051},
+		"note":   "Bearer abc123",
+	})
+	server, _ := got["server"].(map[string]interface{})
+	if server["admin_token"] != "[REDACTED]" || server["port"] != 50051 {
+		t.Errorf("server = %v, want only admin_token redacted", server)
+	}
+	if got["note"] != "[REDACTED]" {
+		t.Errorf("note = %v, want the bearer token redacted", got["note"])
+	}
+}
+
+func TestNewRedactorRejectsInvalidPatterns(t *testing.T) {
+	if _, err := logger.NewRedactor(logger.RedactionConfig{Keys: []string{"[bad"}}); err == nil {
+		t.Error("invalid key glob accepted")
+	}
+	if _, err := logger.NewRedactor(logger.RedactionConfig{Patterns: []string{"(bad"}}); err == nil {
+		t.Error("invalid regex accepted")
+	}
+}
@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+)
+
+// slog has no fatal or panic levels; these sit above slog.LevelError
+const (
+	SlogLevelFatal = slog.Level(12)
+	SlogLevelPanic = slog.Level(16)
+)
+
+// SlogHandler is a slog.Handler that writes records to a Logger, so code
+// using log/slog ends up in the same output as everything else.
+// Groups become dotted key prefixes.
+type SlogHandler struct {
+	logger Logger
+	group  string
+}
+
+// NewSlogHandler returns a handler that writes to l
+func NewSlogHandler(l Logger) *SlogHandler {
+	return &SlogHandler{logger: l}
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= toSlogLevel(h.logger.GetLevel())
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make([]Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.group, a)
+		return true
+	})
+
+	l := h.logger
+	if ctx != nil {
+		l = l.WithContext(ctx)
+	}
+
+	// records are never allowed to exit or panic the process
+	level := fromSlogLevel(r.Level)
+	if level > ErrorLevel {
+		level = ErrorLevel
+	}
+
+	// report the slog call site rather than this handler
+	if cl, ok := l.(callerLogger); ok && r.PC != 0 {
+		cl.logAt(level, r.PC, r.Message, fields)
+		return nil
+	}
+
+	logAtLevel(l, level, r.Message, fields)
+	return nil
+}
+
+// callerLogger is implemented by loggers that can attribute an entry to a
+// given program counter instead of their caller
+type callerLogger interface {
+	logAt(level Level, pc uintptr, msg string, fields []Field)
+}
+
+// logAtLevel writes at Debug to Error; higher levels are written as Error
+// so a bridged record never exits or panics the process
+func logAtLevel(l Logger, level Level, msg string, fields []Field) {
+	switch {
+	case level >= ErrorLevel:
+		l.Error(msg, fields...)
+	case level == WarnLevel:
+		l.Warn(msg, fields...)
+	case level == InfoLevel:
+		l.Info(msg, fields...)
+	default:
+		l.Debug(msg, fields...)
+	}
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	fields := make([]Field, 0, len(attrs))
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.group, a)
+	}
+	return &SlogHandler{logger: h.logger.With(fields...), group: h.group}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, group: joinKey(h.group, name)}
+}
+
+// appendAttr flattens a into fields, following slog's rules for
+// empty attributes and inline groups
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		if len(group) == 0 {
+			return fields
+		}
+		if a.Key != "" {
+			prefix = joinKey(prefix, a.Key)
+		}
+		for _, ga := range group {
+			fields = appendAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+
+	return append(fields, Field{Key: joinKey(prefix, a.Key), Value: a.Value.Any()})
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// SlogLogger is a Logger backed by any slog.Handler, e.g. slog.NewJSONHandler
+// or a handler supplied by a third-party library
+type SlogLogger struct {
+	handler slog.Handler
+	level   *slog.LevelVar
+	ctx     context.Context
+}
+
+// NewSlogLogger returns a Logger that writes to handler at InfoLevel and above
+func NewSlogLogger(handler slog.Handler) *SlogLogger {
+	level := &slog.LevelVar{}
+	level.Set(slog.LevelInfo)
+	return &SlogLogger{handler: handler, level: level, ctx: context.Background()}
+}
+
+func (s *SlogLogger) Debug(msg string, fields ...Field) {
+	s.log(slog.LevelDebug, msg, fields)
+}
+
+func (s *SlogLogger) Info(msg string, fields ...Field) {
+	s.log(slog.LevelInfo, msg, fields)
+}
+
+func (s *SlogLogger) Warn(msg string, fields ...Field) {
+	s.log(slog.LevelWarn, msg, fields)
+}
+
+func (s *SlogLogger) Error(msg string, fields ...Field) {
+	s.log(slog.LevelError, msg, fields)
+}
+
+func (s *SlogLogger) Fatal(msg string, fields ...Field) {
+	s.log(SlogLevelFatal, msg, fields)
+	os.Exit(1)
+}
+
+func (s *SlogLogger) Panic(msg string, fields ...Field) {
+	s.log(SlogLevelPanic, msg, fields)
+	panic(msg)
+}
+
+// WithContext adds the correlation IDs stored in ctx and passes ctx to the
+// handler with every record
+func (s *SlogLogger) WithContext(ctx context.Context) Logger {
+	handler := s.handler
+	if fields := ContextFields(ctx); len(fields) > 0 {
+		handler = handler.WithAttrs(toSlogAttrs(fields))
+	}
+	return &SlogLogger{handler: handler, level: s.level, ctx: ctx}
+}
+
+func (s *SlogLogger) With(fields ...Field) Logger {
+	if len(fields) == 0 {
+		return s
+	}
+	return &SlogLogger{handler: s.handler.WithAttrs(toSlogAttrs(fields)), level: s.level, ctx: s.ctx}
+}
+
+func (s *SlogLogger) WithPrefix(prefix string) Logger {
+	return s.With(FieldString("prefix", prefix))
+}
+
+func (s *SlogLogger) Sync() error {
+	return nil
+}
+
+func (s *SlogLogger) SetLevel(level Level) {
+	s.level.Set(toSlogLevel(level))
+}
+
+func (s *SlogLogger) GetLevel() Level {
+	return fromSlogLevel(s.level.Level())
+}
+
+func (s *SlogLogger) log(level slog.Level, msg string, fields []Field) {
+	if level < s.level.Level() || !s.handler.Enabled(s.ctx, level) {
+		return
+	}
+
+	// skip runtime.Callers, log and the exported method
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(toSlogAttrs(fields)...)
+	_ = s.handler.Handle(s.ctx, r)
+}
+
+func toSlogAttrs(fields []Field) []slog.Attr {
+	attrs := make([]slog.Attr, len(fields))
+	for i, f := range fields {
+		attrs[i] = slog.Any(f.Key, f.Value)
+	}
+	return attrs
+}
+
+func toSlogLevel(level Level) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	case FatalLevel:
+		return SlogLevelFatal
+	case PanicLevel:
+		return SlogLevelPanic
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level >= SlogLevelPanic:
+		return PanicLevel
+	case level >= SlogLevelFatal:
+		return FatalLevel
+	case level >= slog.LevelError:
+		return ErrorLevel
+	case level >= slog.LevelWarn:
+		return WarnLevel
+	case level >= slog.LevelInfo:
+		return InfoLevel
+	default:
+		return DebugLevel
+	}
+}
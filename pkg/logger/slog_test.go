@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newObservedLogger returns a ZapLogger at level that records its entries
+func newObservedLogger(level Level) (*ZapLogger, *observer.ObservedLogs) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	atomic := zap.NewAtomicLevelAt(toZapLevel(level))
+	l := zap.New(newLevelCore(obs, atomic), zap.AddCaller(), zap.AddCallerSkip(1))
+	return &ZapLogger{logger: l, level: atomic}, logs
+}
+
+func TestSlogHandlerGroupsAndAttrs(t *testing.T) {
+	z, logs := newObservedLogger(DebugLevel)
+	log := slog.New(NewSlogHandler(z)).With("service", "api").WithGroup("req").With("id", 7)
+
+	log.Info("handled",
+		"path", "/health",
+		slog.Group("user", "name", "bob"),
+		slog.Group("", "inline", true),
+		slog.Group("empty"),
+		slog.Attr{},
+	)
+
+	entries := logs.AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("%d entries, want 1", len(entries))
+	}
+	want := map[string]interface{}{
+		"service":       "api",
+		"req.id":        int64(7),
+		"req.path":      "/health",
+		"req.user.name": "bob",
+		"req.inline":    true,
+	}
+	got := entries[0].ContextMap()
+	if len(got) != len(want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, got[k], v)
+		}
+	}
+	if !strings.HasSuffix(entries[0].Caller.File, "slog_test.go") {
+		t.Errorf("caller = %s, want the slog call site", entries[0].Caller.File)
+	}
+}
+
+func TestSlogHandlerLevels(t *testing.T) {
+	z, logs := newObservedLogger(WarnLevel)
+	h := NewSlogHandler(z)
+	log := slog.New(h)
+
+	if h.Enabled(context.Background(), slog.LevelInfo) || !h.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Enabled does not follow the logger level")
+	}
+
+	log.Info("dropped")
+	log.Warn("kept")
+	log.Log(context.Background(), SlogLevelFatal, "fatal from a library")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(entries))
+	}
+	if entries[0].Level != zapcore.WarnLevel {
+		t.Errorf("first entry at %s, want warn", entries[0].Level)
+	}
+	// a bridged record must never exit the process
+	if entries[1].Level != zapcore.ErrorLevel {
+		t.Errorf("fatal record written at %s, want error", entries[1].Level)
+	}
+}
+
+func TestSlogHandlerContext(t *testing.T) {
+	z, logs := newObservedLogger(DebugLevel)
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+
+	slog.New(NewSlogHandler(z)).InfoContext(ctx, "with context")
+
+	if got := logs.AllUntimed()[0].ContextMap()[FieldKeyRequestID]; got != "req-1" {
+		t.Errorf("request_id = %v, want req-1", got)
+	}
+}
+
+// decodeLines decodes every JSON line written by a slog.JSONHandler
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("bad JSON line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogLogger(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug, AddSource: true}))
+
+	if l.GetLevel() != InfoLevel {
+		t.Errorf("default level = %s, want info", l.GetLevel())
+	}
+	l.Debug("hidden")
+
+	ctx := ContextWithTrace(ContextWithTenant(context.Background(), "acme"), "t1", "s1")
+	l.With(FieldString("service", "api")).WithContext(ctx).Warn("slow", FieldInt("ms", 900))
+
+	l.SetLevel(DebugLevel)
+	l.WithPrefix("nats").Debug("shown")
+
+	records := decodeLines(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("%d records, want 2:\n%s", len(records), buf.String())
+	}
+
+	warn := records[0]
+	want := map[string]interface{}{
+		"level":         "WARN",
+		"msg":           "slow",
+		"service":       "api",
+		"ms":            float64(900),
+		FieldKeyTenant:  "acme",
+		FieldKeyTraceID: "t1",
+		FieldKeySpanID:  "s1",
+	}
+	for k, v := range want {
+		if warn[k] != v {
+			t.Errorf("%s = %v, want %v", k, warn[k], v)
+		}
+	}
+	if source, _ := warn["source"].(map[string]interface{}); !strings.HasSuffix(source["file"].(string), "slog_test.go") {
+		t.Errorf("source = %v, want the calling test", warn["source"])
+	}
+
+	if debug := records[1]; debug["msg"] != "shown" || debug["prefix"] != "nats" {
+		t.Errorf("debug record = %v", debug)
+	}
+}
+
+func TestSlogLevelMapping(t *testing.T) {
+	for _, level := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel, PanicLevel} {
+		if got := fromSlogLevel(toSlogLevel(level)); got != level {
+			t.Errorf("%s round-trips to %s", level, got)
+		}
+	}
+	if got := fromSlogLevel(slog.LevelWarn + 2); got != WarnLevel {
+		t.Errorf("level between warn and error maps to %s, want warn", got)
+	}
+}
@@ -0,0 +1,309 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SamplingConfig keeps the first Initial entries with the same level and
+// message in each Tick, then every Thereafter-th one (none if zero).
+// Sampling is disabled when Initial and Thereafter are both zero.
+type SamplingConfig struct {
+	Initial    int           `json:"initial" yaml:"initial"`
+	Thereafter int           `json:"thereafter" yaml:"thereafter"`
+	Tick       time.Duration `json:"tick" yaml:"tick"` // default 1s
+}
+
+func (c SamplingConfig) enabled() bool {
+	return c.Initial > 0 || c.Thereafter > 0
+}
+
+// RateLimitConfig allows at most PerMessage entries with the same level and
+// message in each Interval. The next entry written after a drop carries a
+// "dropped" field with the number of entries left out. Zero disables it.
+type RateLimitConfig struct {
+	PerMessage int           `json:"per_message" yaml:"per_message"`
+	Interval   time.Duration `json:"interval" yaml:"interval"` // default 1s
+}
+
+// DedupConfig collapses identical entries (same level, message and fields)
+// at Level or above. The first one is written at once; repeats within
+// Window are counted and written as a single entry with a "repeated" field
+// when the window ends. Fields added with With are not compared, so the
+// same failure in different requests is collapsed too. Zero disables it.
+type DedupConfig struct {
+	Window time.Duration `json:"window" yaml:"window"`
+	Level  Level         `json:"level" yaml:"level"`
+}
+
+const (
+	defaultVolumeInterval = time.Second
+
+	// keys tracked by the rate limiter; expired ones are purged when full
+	maxRateLimitKeys = 4096
+
+	// distinct entries the deduper holds within a window
+	maxDedupKeys = 4096
+)
+
+// withVolumeControl wraps a single sink with rate limiting and deduplication
+func withVolumeControl(core zapcore.Core, config Config) zapcore.Core {
+	if config.RateLimit.PerMessage > 0 {
+		interval := config.RateLimit.Interval
+		if interval <= 0 {
+			interval = defaultVolumeInterval
+		}
+		core = &rateLimitCore{Core: core, limiter: newRateLimiter(config.RateLimit.PerMessage, interval)}
+	}
+
+	if config.Dedup.Window > 0 {
+		core = &dedupCore{Core: core, dedup: newDeduper(config.Dedup.Window, toZapLevel(config.Dedup.Level))}
+	}
+
+	return core
+}
+
+// withSampling applies zap's sampler in front of all sinks
+func withSampling(core zapcore.Core, config SamplingConfig) zapcore.Core {
+	if !config.enabled() {
+		return core
+	}
+
+	tick := config.Tick
+	if tick <= 0 {
+		tick = defaultVolumeInterval
+	}
+	return zapcore.NewSamplerWithOptions(core, tick, config.Initial, config.Thereafter)
+}
+
+func entryKey(entry zapcore.Entry) string {
+	return entry.LoggerName + "|" + entry.Level.String() + "|" + entry.Message
+}
+
+// rateLimitCore drops entries over the per-message limit. The decision
+// needs the entry to be written, so it wraps a single sink rather than a
+// tee, whose Write would bypass the level of each sink.
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *rateLimiter
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiter: c.limiter}
+}
+
+func (c *rateLimitCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *rateLimitCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	ok, dropped := c.limiter.allow(entryKey(entry), entry.Time)
+	if !ok {
+		return nil
+	}
+	if dropped > 0 {
+		fields = append(fields[:len(fields):len(fields)], zap.Int("dropped", dropped))
+	}
+	return c.Core.Write(entry, fields)
+}
+
+type rateLimiter struct {
+	mu         sync.Mutex
+	perMessage int
+	interval   time.Duration
+	windows    map[string]*rateWindow
+	lastPurge  time.Time
+}
+
+type rateWindow struct {
+	start   time.Time
+	count   int
+	dropped int
+}
+
+func newRateLimiter(perMessage int, interval time.Duration) *rateLimiter {
+	return &rateLimiter{
+		perMessage: perMessage,
+		interval:   interval,
+		windows:    make(map[string]*rateWindow),
+	}
+}
+
+// allow reports whether an entry may be written and how many were dropped
+// since the last one that was
+func (r *rateLimiter) allow(key string, now time.Time) (bool, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.windows[key]
+	if !ok {
+		if len(r.windows) >= maxRateLimitKeys && now.Sub(r.lastPurge) >= r.interval {
+			r.purge(now)
+		}
+		// more distinct messages than the limiter can track are written
+		// unlimited until the next purge frees space
+		if len(r.windows) >= maxRateLimitKeys {
+			return true, 0
+		}
+		w = &rateWindow{start: now}
+		r.windows[key] = w
+	}
+
+	if now.Sub(w.start) >= r.interval {
+		w.start = now
+		w.count = 0
+	}
+
+	if w.count >= r.perMessage {
+		w.dropped++
+		return false, 0
+	}
+
+	w.count++
+	dropped := w.dropped
+	w.dropped = 0
+	return true, dropped
+}
+
+// purge forgets expired windows. The drop count of an evicted window is
+// lost: the next entry with its message does not report it.
+func (r *rateLimiter) purge(now time.Time) {
+	r.lastPurge = now
+	for key, w := range r.windows {
+		if now.Sub(w.start) >= r.interval {
+			delete(r.windows, key)
+		}
+	}
+}
+
+// dedupCore collapses identical entries written within a window
+type dedupCore struct {
+	zapcore.Core
+	dedup *deduper
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), dedup: c.dedup}
+}
+
+func (c *dedupCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *dedupCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.Level < c.dedup.level {
+		return c.Core.Write(entry, fields)
+	}
+	if c.dedup.seen(c.Core, entry, fields) {
+		return nil
+	}
+	return c.Core.Write(entry, fields)
+}
+
+// Sync writes pending repeat counts before syncing the sink
+func (c *dedupCore) Sync() error {
+	c.dedup.flushAll()
+	return c.Core.Sync()
+}
+
+type deduper struct {
+	mu      sync.Mutex
+	window  time.Duration
+	level   zapcore.Level
+	pending map[string]*duplicate
+}
+
+type duplicate struct {
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zapcore.Field
+	count  int
+	timer  *time.Timer
+}
+
+func newDeduper(window time.Duration, level zapcore.Level) *deduper {
+	return &deduper{window: window, level: level, pending: make(map[string]*duplicate)}
+}
+
+// seen reports whether an identical entry was written within the window.
+// The first occurrence starts the window and is written by the caller.
+func (d *deduper) seen(core zapcore.Core, entry zapcore.Entry, fields []zapcore.Field) bool {
+	key := entryKey(entry) + "|" + fieldsKey(fields)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if dup, ok := d.pending[key]; ok {
+		dup.count++
+		return true
+	}
+	// more distinct entries than the deduper can hold are written unchanged
+	// until windows end and free space
+	if len(d.pending) >= maxDedupKeys {
+		return false
+	}
+
+	dup := &duplicate{core: core, entry: entry, fields: fields}
+	dup.timer = time.AfterFunc(d.window, func() { d.flush(key) })
+	d.pending[key] = dup
+	return false
+}
+
+// flush ends the window for key and writes the repeat count, if any
+func (d *deduper) flush(key string) {
+	d.mu.Lock()
+	dup, ok := d.pending[key]
+	delete(d.pending, key)
+	d.mu.Unlock()
+
+	if ok {
+		dup.writeRepeats()
+	}
+}
+
+func (d *deduper) flushAll() {
+	d.mu.Lock()
+	pending := d.pending
+	d.pending = make(map[string]*duplicate)
+	d.mu.Unlock()
+
+	for _, dup := range pending {
+		dup.timer.Stop()
+		dup.writeRepeats()
+	}
+}
+
+func (dup *duplicate) writeRepeats() {
+	if dup.count == 0 {
+		return
+	}
+
+	entry := dup.entry
+	entry.Time = time.Now()
+	fields := append(dup.fields[:len(dup.fields):len(dup.fields)], zap.Int("repeated", dup.count))
+	dup.core.Write(entry, fields)
+}
+
+// fieldsKey renders fields so that identical values produce the same key
+func fieldsKey(fields []zapcore.Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	// fmt prints maps with sorted keys
+	return fmt.Sprint(enc.Fields)
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	r := newRateLimiter(2, time.Second)
+	start := time.Now()
+
+	steps := []struct {
+		after       time.Duration
+		ok          bool
+		wantDropped int
+	}{
+		{0, true, 0},
+		{100 * time.Millisecond, true, 0},
+		{200 * time.Millisecond, false, 0},
+		{300 * time.Millisecond, false, 0},
+		{time.Second, true, 2}, // new window reports the drops
+		{1100 * time.Millisecond, true, 0},
+		{1200 * time.Millisecond, false, 0},
+	}
+	for i, step := range steps {
+		ok, dropped := r.allow("key", start.Add(step.after))
+		if ok != step.ok || dropped != step.wantDropped {
+			t.Errorf("step %d: allow = %v, %d; want %v, %d", i, ok, dropped, step.ok, step.wantDropped)
+		}
+	}
+
+	if ok, _ := r.allow("other", start.Add(1200*time.Millisecond)); !ok {
+		t.Error("a different key shared the limit")
+	}
+}
+
+func TestRateLimiterPurgesExpiredWindows(t *testing.T) {
+	r := newRateLimiter(1, time.Second)
+	start := time.Now()
+
+	for i := 0; i < maxRateLimitKeys; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		r.allow(key, start)
+		r.allow(key, start) // leaves a pending drop
+	}
+
+	later := start.Add(2 * time.Second)
+	if ok, _ := r.allow("new", later); !ok {
+		t.Fatal("new key rejected")
+	}
+	if len(r.windows) != 1 {
+		t.Errorf("%d windows after purge, want only the new one; windows with drops must expire too", len(r.windows))
+	}
+}
+
+func TestRateLimiterStaysBounded(t *testing.T) {
+	r := newRateLimiter(1, time.Minute)
+	start := time.Now()
+
+	for i := 0; i < maxRateLimitKeys; i++ {
+		r.allow(fmt.Sprintf("key-%d", i), start)
+	}
+
+	// every window is live, so new keys are let through untracked
+	for i := 0; i < 10; i++ {
+		if ok, _ := r.allow("overflow", start.Add(time.Duration(i)*time.Millisecond)); !ok {
+			t.Fatal("untracked key was limited")
+		}
+	}
+	if len(r.windows) != maxRateLimitKeys {
+		t.Errorf("%d windows, want at most %d", len(r.windows), maxRateLimitKeys)
+	}
+	if !r.lastPurge.Equal(start) {
+		t.Errorf("purged again within the interval")
+	}
+}
+
+func TestRateLimitCoreAddsDroppedField(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core := withVolumeControl(obs, Config{RateLimit: RateLimitConfig{PerMessage: 1, Interval: time.Hour}})
+	log := zap.New(core)
+
+	log.Info("busy")
+	log.Info("busy")
+	log.Info("busy")
+	if logs.Len() != 1 {
+		t.Fatalf("%d entries written, want 1", logs.Len())
+	}
+
+	limiter := core.(*rateLimitCore).limiter
+	limiter.windows[entryKey(zapcore.Entry{Level: zapcore.InfoLevel, Message: "busy"})].start = time.Time{}
+
+	log.Info("busy")
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("%d entries written, want 2", len(entries))
+	}
+	if got := entries[1].ContextMap()["dropped"]; got != int64(2) {
+		t.Errorf("dropped = %v, want 2", got)
+	}
+}
+
+func TestDedupCollapsesRepeats(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core := withVolumeControl(obs, Config{Dedup: DedupConfig{Window: time.Hour, Level: ErrorLevel}})
+	log := zap.New(core)
+
+	for i := 0; i < 3; i++ {
+		log.Error("db down", zap.String("host", "a"))
+	}
+	log.Error("db down", zap.String("host", "b"))
+	log.Warn("slow", zap.String("host", "a"))
+	log.Warn("slow", zap.String("host", "a"))
+
+	// first of each error plus both warnings, below the dedup level
+	if logs.Len() != 4 {
+		t.Fatalf("%d entries before the window ends, want 4", logs.Len())
+	}
+
+	core.Sync()
+	entries := logs.AllUntimed()
+	if len(entries) != 5 {
+		t.Fatalf("%d entries after Sync, want 5", len(entries))
+	}
+	last := entries[4]
+	if last.Message != "db down" || last.ContextMap()["host"] != "a" || last.ContextMap()["repeated"] != int64(2) {
+		t.Errorf("summary entry = %s %v, want db down from host a repeated 2 times", last.Message, last.ContextMap())
+	}
+}
+
+func TestDedupWindowEnds(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core := withVolumeControl(obs, Config{Dedup: DedupConfig{Window: 20 * time.Millisecond, Level: ErrorLevel}})
+	log := zap.New(core)
+
+	log.Error("db down")
+	log.Error("db down")
+
+	deadline := time.Now().Add(time.Second)
+	for logs.Len() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if logs.Len() != 2 {
+		t.Fatalf("%d entries, want the first one and a summary", logs.Len())
+	}
+
+	log.Error("db down")
+	if logs.Len() != 3 {
+		t.Error("entry after the window was not written")
+	}
+}
+
+func TestDedupStaysBounded(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core := withVolumeControl(obs, Config{Dedup: DedupConfig{Window: time.Hour, Level: ErrorLevel}})
+	log := zap.New(core)
+	defer core.Sync()
+
+	for i := 0; i < maxDedupKeys; i++ {
+		log.Error("db down", zap.Int("shard", i))
+	}
+
+	// every window is live, so new entries are written through untracked
+	for i := 0; i < 3; i++ {
+		log.Error("overflow")
+	}
+	if logs.Len() != maxDedupKeys+3 {
+		t.Errorf("%d entries written, want %d", logs.Len(), maxDedupKeys+3)
+	}
+	if pending := len(core.(*dedupCore).dedup.pending); pending != maxDedupKeys {
+		t.Errorf("%d pending entries, want at most %d", pending, maxDedupKeys)
+	}
+}
@@ -3,6 +3,7 @@ package logger
 import (
 	"context"
 	"os"
+	"runtime"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -11,13 +12,13 @@ import (
 
 type ZapLogger struct {
 	logger *zap.Logger
-	level  Level
+	level  zap.AtomicLevel // shared with children created by With
 	config Config
 }
 
 func NewZapLogger(config Config) (*ZapLogger, error) {
 
-	zapLevel := toZapLevel(config.Level)
+	level := zap.NewAtomicLevelAt(toZapLevel(config.Level))
 
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "ts",
@@ -33,31 +34,27 @@ func NewZapLogger(config Config) (*ZapLogger, error) {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
-	var encoder zapcore.Encoder
-	if config.Encoding == "console" {
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
-	} else {
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
-	}
-
-	var writeSyncer zapcore.WriteSyncer
-	if config.OutputPath == "" || config.OutputPath == "stdout" {
-		writeSyncer = zapcore.AddSync(os.Stdout)
-	} else if config.OutputPath == "stderr" {
-		writeSyncer = zapcore.AddSync(os.Stderr)
-	} else {
-
-		lumberjackLogger := &lumberjack.Logger{
-			Filename:   config.OutputPath,
+	sinks := config.Sinks
+	if len(sinks) == 0 {
+		sinks = []SinkConfig{{
+			Level:      DebugLevel,
+			Encoding:   config.Encoding,
+			OutputPath: config.OutputPath,
 			MaxSize:    config.MaxSize,
 			MaxBackups: config.MaxBackups,
 			MaxAge:     config.MaxAge,
 			Compress:   config.Compress,
-		}
-		writeSyncer = zapcore.AddSync(lumberjackLogger)
+		}}
+	}
+
+	cores := make([]zapcore.Core, len(sinks))
+	for i, sink := range sinks {
+		cores[i] = withVolumeControl(newSinkCore(encoderConfig, sink), config)
 	}
 
-	core := zapcore.NewCore(encoder, writeSyncer, zapLevel)
+	// the sinks only apply their own minimum; levelCore applies the
+	// logger's changeable level in front of all of them
+	core := newLevelCore(withSampling(zapcore.NewTee(cores...), config.Sampling), level)
 
 	options := []zap.Option{
 		zap.AddCaller(),
@@ -72,7 +69,7 @@ func NewZapLogger(config Config) (*ZapLogger, error) {
 
 	return &ZapLogger{
 		logger: logger,
-		level:  config.Level,
+		level:  level,
 		config: config,
 	}, nil
 }
@@ -101,9 +98,54 @@ func (z *ZapLogger) Panic(msg string, fields ...Field) {
 	z.logger.Panic(msg, toZapFields(fields)...)
 }
 
-func (z *ZapLogger) WithContext(ctx context.Context) Logger {
+func newSinkCore(encoderConfig zapcore.EncoderConfig, sink SinkConfig) zapcore.Core {
+	var encoder zapcore.Encoder
+	if sink.Encoding == "console" {
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	}
+
+	var writeSyncer zapcore.WriteSyncer
+	if sink.OutputPath == "" || sink.OutputPath == "stdout" {
+		writeSyncer = zapcore.AddSync(os.Stdout)
+	} else if sink.OutputPath == "stderr" {
+		writeSyncer = zapcore.AddSync(os.Stderr)
+	} else {
+
+		lumberjackLogger := &lumberjack.Logger{
+			Filename:   sink.OutputPath,
+			MaxSize:    sink.MaxSize,
+			MaxBackups: sink.MaxBackups,
+			MaxAge:     sink.MaxAge,
+			Compress:   sink.Compress,
+		}
+		writeSyncer = zapcore.AddSync(lumberjackLogger)
+	}
 
-	return z
+	return zapcore.NewCore(encoder, writeSyncer, toZapLevel(sink.Level))
+}
+
+// logAt writes an entry whose caller is pc instead of the calling frame
+func (z *ZapLogger) logAt(level Level, pc uintptr, msg string, fields []Field) {
+	ce := z.logger.Check(toZapLevel(level), msg)
+	if ce == nil {
+		return
+	}
+	if ce.Caller.Defined {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+	ce.Write(toZapFields(fields)...)
+}
+
+// WithContext adds the correlation IDs stored in ctx (see ContextFields)
+func (z *ZapLogger) WithContext(ctx context.Context) Logger {
+	fields := ContextFields(ctx)
+	if len(fields) == 0 {
+		return z
+	}
+	return z.With(fields...)
 }
 
 func (z *ZapLogger) With(fields ...Field) Logger {
@@ -115,6 +157,24 @@ func (z *ZapLogger) With(fields ...Field) Logger {
 	}
 }
 
+// Named returns a child logger whose name is added to the "logger" key and
+// whose level can be changed independently. It starts at z's current level.
+func (z *ZapLogger) Named(name string) Logger {
+	level := zap.NewAtomicLevelAt(z.level.Level())
+	named := z.logger.Named(name).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if lc, ok := core.(*levelCore); ok {
+			core = lc.Core
+		}
+		return newLevelCore(core, level)
+	}))
+
+	return &ZapLogger{
+		logger: named,
+		level:  level,
+		config: z.config,
+	}
+}
+
 func (z *ZapLogger) WithPrefix(prefix string) Logger {
 	return z.With(FieldString("prefix", prefix))
 }
@@ -123,13 +183,41 @@ func (z *ZapLogger) Sync() error {
 	return z.logger.Sync()
 }
 
+// SetLevel changes the level of z and every logger derived from it with
+// With, WithContext or WithPrefix
 func (z *ZapLogger) SetLevel(level Level) {
-	z.level = level
-
+	z.level.SetLevel(toZapLevel(level))
 }
 
 func (z *ZapLogger) GetLevel() Level {
-	return z.level
+	return fromZapLevel(z.level.Level())
+}
+
+// levelCore filters entries by a level that can change after the core
+// has been created, so that one zap.AtomicLevel controls a logger and all
+// of its children
+type levelCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+func newLevelCore(core zapcore.Core, level zap.AtomicLevel) *levelCore {
+	return &levelCore{Core: core, level: level}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(entry.Level) {
+		return ce
+	}
+	return c.Core.Check(entry, ce)
 }
 
 func toZapLevel(level Level) zapcore.Level {
@@ -151,9 +239,34 @@ func toZapLevel(level Level) zapcore.Level {
 	}
 }
 
+func fromZapLevel(level zapcore.Level) Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return DebugLevel
+	case zapcore.InfoLevel:
+		return InfoLevel
+	case zapcore.WarnLevel:
+		return WarnLevel
+	case zapcore.ErrorLevel:
+		return ErrorLevel
+	case zapcore.FatalLevel:
+		return FatalLevel
+	case zapcore.PanicLevel, zapcore.DPanicLevel:
+		return PanicLevel
+	default:
+		return InfoLevel
+	}
+}
+
 func toZapFields(fields []Field) []zap.Field {
 	zapFields := make([]zap.Field, len(fields))
 	for i, f := range fields {
+		// errors are written by their message; zap.Any would add an
+		// errorVerbose field with the %+v form, stack frames included
+		if err, ok := f.Value.(error); ok && err != nil {
+			zapFields[i] = zap.String(f.Key, err.Error())
+			continue
+		}
 		zapFields[i] = zap.Any(f.Key, f.Value)
 	}
 	return zapFields
@@ -0,0 +1,237 @@
+package registry
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/errors"
+)
+
+// load balancing strategies accepted by NewBalancer
+const (
+	StrategyRoundRobin         = "round_robin"
+	StrategyLeastConnections   = "least_connections"
+	StrategyRandom             = "random"
+	StrategyWeightedRoundRobin = "weighted_round_robin"
+	StrategyConsistentHash     = "consistent_hash"
+)
+
+// Instance is a registered service as seen by a balancer
+type Instance struct {
+	Service           *pb.Service
+	Weight            int
+	ActiveConnections int
+}
+
+// Balancer picks one instance out of a set of candidates.
+// The key is only used by strategies with request affinity (consistent_hash).
+type Balancer interface {
+	Pick(instances []Instance, key string) (*pb.Service, error)
+	Name() string
+}
+
+// NewBalancer creates a balancer for the given strategy name.
+// An empty strategy defaults to round_robin.
+func NewBalancer(strategy string) (Balancer, error) {
+	switch strategy {
+	case "", StrategyRoundRobin:
+		return NewRoundRobin(), nil
+	case StrategyLeastConnections:
+		return NewLeastConnections(), nil
+	case StrategyRandom:
+		return NewRandom(), nil
+	case StrategyWeightedRoundRobin:
+		return NewWeightedRoundRobin(), nil
+	case StrategyConsistentHash:
+		return NewConsistentHash(defaultReplicas), nil
+	default:
+		return nil, errors.Newf(errors.CodeConfigError,
+			"unknown load balancing strategy '%s'", strategy)
+	}
+}
+
+// RoundRobin cycles through instances of each service in ID order. A pick
+// across several services keeps its own position.
+type RoundRobin struct {
+	mu   sync.Mutex
+	next map[string]int
+}
+
+func NewRoundRobin() *RoundRobin {
+	return &RoundRobin{next: make(map[string]int)}
+}
+
+func (b *RoundRobin) Name() string { return StrategyRoundRobin }
+
+func (b *RoundRobin) Pick(instances []Instance, key string) (*pb.Service, error) {
+	if len(instances) == 0 {
+		return nil, errNoInstances()
+	}
+	sorted := sortedByID(instances)
+	group := groupKey(sorted)
+
+	b.mu.Lock()
+	i := b.next[group] % len(sorted)
+	b.next[group] = i + 1
+	b.mu.Unlock()
+
+	return sorted[i].Service, nil
+}
+
+// LeastConnections picks the instance reporting the fewest active connections.
+// Ties are broken randomly so idle instances share new traffic.
+type LeastConnections struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func NewLeastConnections() *LeastConnections {
+	return &LeastConnections{rnd: newRand()}
+}
+
+func (b *LeastConnections) Name() string { return StrategyLeastConnections }
+
+func (b *LeastConnections) Pick(instances []Instance, key string) (*pb.Service, error) {
+	if len(instances) == 0 {
+		return nil, errNoInstances()
+	}
+
+	var best []Instance
+	for _, inst := range instances {
+		switch {
+		case len(best) == 0 || inst.ActiveConnections < best[0].ActiveConnections:
+			best = []Instance{inst}
+		case inst.ActiveConnections == best[0].ActiveConnections:
+			best = append(best, inst)
+		}
+	}
+
+	b.mu.Lock()
+	i := b.rnd.Intn(len(best))
+	b.mu.Unlock()
+
+	return best[i].Service, nil
+}
+
+// Random picks a uniformly random instance
+type Random struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func NewRandom() *Random {
+	return &Random{rnd: newRand()}
+}
+
+func (b *Random) Name() string { return StrategyRandom }
+
+func (b *Random) Pick(instances []Instance, key string) (*pb.Service, error) {
+	if len(instances) == 0 {
+		return nil, errNoInstances()
+	}
+
+	b.mu.Lock()
+	i := b.rnd.Intn(len(instances))
+	b.mu.Unlock()
+
+	return instances[i].Service, nil
+}
+
+// WeightedRoundRobin implements smooth weighted round-robin (as in nginx):
+// an instance with weight 3 is picked three times as often as one with
+// weight 1, without sending those picks back to back.
+type WeightedRoundRobin struct {
+	mu      sync.Mutex
+	current map[string]map[string]int // group key -> instance ID -> current weight
+}
+
+func NewWeightedRoundRobin() *WeightedRoundRobin {
+	return &WeightedRoundRobin{current: make(map[string]map[string]int)}
+}
+
+func (b *WeightedRoundRobin) Name() string { return StrategyWeightedRoundRobin }
+
+func (b *WeightedRoundRobin) Pick(instances []Instance, key string) (*pb.Service, error) {
+	if len(instances) == 0 {
+		return nil, errNoInstances()
+	}
+	sorted := sortedByID(instances)
+	group := groupKey(sorted)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// rebuild the group's state so instances that went away are dropped
+	prev := b.current[group]
+	current := make(map[string]int, len(sorted))
+
+	total := 0
+	best := -1
+	for i, inst := range sorted {
+		weight := inst.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		total += weight
+
+		id := inst.Service.Id
+		current[id] = prev[id] + weight
+		if best < 0 || current[id] > current[sorted[best].Service.Id] {
+			best = i
+		}
+	}
+	current[sorted[best].Service.Id] -= total
+	b.current[group] = current
+
+	return sorted[best].Service, nil
+}
+
+// Helper functions
+
+func errNoInstances() *errors.Error {
+	return errors.New(errors.CodeServiceNotFound, "no instances available")
+}
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// groupKey names the set of services the instances belong to, so that a
+// pick across several services does not share state with any one of them
+func groupKey(instances []Instance) string {
+	name := instances[0].Service.Name
+	var names []string
+	for _, inst := range instances[1:] {
+		if inst.Service.Name != name && names == nil {
+			names = []string{name}
+		}
+		if names != nil {
+			names = append(names, inst.Service.Name)
+		}
+	}
+	if names == nil {
+		return name
+	}
+
+	sort.Strings(names)
+	unique := names[:1]
+	for _, n := range names[1:] {
+		if n != unique[len(unique)-1] {
+			unique = append(unique, n)
+		}
+	}
+	return strings.Join(unique, ",")
+}
+
+func sortedByID(instances []Instance) []Instance {
+	sorted := make([]Instance, len(instances))
+	copy(sorted, instances)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Service.Id < sorted[j].Service.Id
+	})
+	return sorted
+}
@@ -0,0 +1,222 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	pb "upm-simple/internal"
+)
+
+func instances(weights map[string]int) []Instance {
+	list := make([]Instance, 0, len(weights))
+	for id, weight := range weights {
+		list = append(list, Instance{Service: &pb.Service{Id: id, Name: "svc"}, Weight: weight})
+	}
+	return list
+}
+
+func TestWeightedRoundRobinDistribution(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights map[string]int
+		want    map[string]int // picks per cycle of the total weight
+	}{
+		{"equal", map[string]int{"a": 1, "b": 1, "c": 1}, map[string]int{"a": 1, "b": 1, "c": 1}},
+		{"weighted", map[string]int{"a": 5, "b": 1, "c": 1}, map[string]int{"a": 5, "b": 1, "c": 1}},
+		{"zero counts as one", map[string]int{"a": 0, "b": 2}, map[string]int{"a": 1, "b": 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewWeightedRoundRobin()
+			list := instances(tt.weights)
+
+			total := 0
+			for _, n := range tt.want {
+				total += n
+			}
+
+			got := make(map[string]int)
+			for i := 0; i < total*10; i++ {
+				svc, err := b.Pick(list, "")
+				if err != nil {
+					t.Fatalf("Pick: %v", err)
+				}
+				got[svc.Id]++
+			}
+			for id, n := range tt.want {
+				if got[id] != n*10 {
+					t.Errorf("%s picked %d times, want %d", id, got[id], n*10)
+				}
+			}
+		})
+	}
+}
+
+func TestWeightedRoundRobinIsSmooth(t *testing.T) {
+	b := NewWeightedRoundRobin()
+	list := instances(map[string]int{"a": 5, "b": 1, "c": 1})
+
+	var seq string
+	for i := 0; i < 7; i++ {
+		svc, _ := b.Pick(list, "")
+		seq += svc.Id
+	}
+	// nginx's smooth weighted round-robin for weights 5, 1, 1
+	if seq != "aabacaa" {
+		t.Errorf("sequence = %s, want aabacaa", seq)
+	}
+}
+
+func TestWeightedRoundRobinDropsRemovedInstances(t *testing.T) {
+	b := NewWeightedRoundRobin()
+	for i := 0; i < 3; i++ {
+		b.Pick(instances(map[string]int{"a": 1, "b": 1, "c": 1}), "")
+	}
+
+	remaining := instances(map[string]int{"a": 1, "b": 1})
+	for i := 0; i < 4; i++ {
+		svc, _ := b.Pick(remaining, "")
+		if svc.Id == "c" {
+			t.Fatal("picked a removed instance")
+		}
+	}
+	if _, ok := b.current["svc"]["c"]; ok {
+		t.Error("state kept for a removed instance")
+	}
+}
+
+func TestConsistentHashIsStable(t *testing.T) {
+	b := NewConsistentHash(0)
+	list := instances(map[string]int{"a": 1, "b": 1, "c": 1})
+	reversed := []Instance{list[2], list[1], list[0]}
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		first, err := b.Pick(list, key)
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		again, _ := b.Pick(reversed, key)
+		if first.Id != again.Id {
+			t.Fatalf("key %s moved from %s to %s when only the order changed", key, first.Id, again.Id)
+		}
+	}
+}
+
+func TestConsistentHashMovesFewKeys(t *testing.T) {
+	b := NewConsistentHash(defaultReplicas)
+	before := instances(map[string]int{"a": 1, "b": 1, "c": 1, "d": 1})
+	after := instances(map[string]int{"a": 1, "b": 1, "c": 1, "d": 1, "e": 1})
+
+	const keys = 2000
+	moved := 0
+	spread := make(map[string]int)
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		old, _ := b.Pick(before, key)
+		cur, _ := b.Pick(after, key)
+		spread[cur.Id]++
+		if old.Id != cur.Id {
+			moved++
+			if cur.Id != "e" {
+				t.Fatalf("key %s moved between existing instances %s and %s", key, old.Id, cur.Id)
+			}
+		}
+	}
+
+	// about 1/5 of the keys should go to the new instance
+	if moved < keys/10 || moved > keys*3/10 {
+		t.Errorf("%d of %d keys moved, want about %d", moved, keys, keys/5)
+	}
+	for id, n := range spread {
+		if n < keys/10 {
+			t.Errorf("%s got only %d of %d keys", id, n, keys)
+		}
+	}
+}
+
+func TestConsistentHashRequiresKey(t *testing.T) {
+	b := NewConsistentHash(defaultReplicas)
+	if _, err := b.Pick(instances(map[string]int{"a": 1}), ""); err == nil {
+		t.Error("Pick without a key succeeded")
+	}
+	if _, err := b.Pick(nil, "key"); err == nil {
+		t.Error("Pick without instances succeeded")
+	}
+}
+
+func TestRoundRobinKeepsStatePerServiceSet(t *testing.T) {
+	api := []Instance{
+		{Service: &pb.Service{Id: "api-1", Name: "api"}},
+		{Service: &pb.Service{Id: "api-2", Name: "api"}},
+	}
+	mixed := append([]Instance{{Service: &pb.Service{Id: "db-1", Name: "db"}}}, api...)
+
+	for _, b := range []Balancer{NewRoundRobin(), NewWeightedRoundRobin()} {
+		t.Run(b.Name(), func(t *testing.T) {
+			var seq string
+			for i := 0; i < 4; i++ {
+				svc, _ := b.Pick(api, "")
+				seq += svc.Id + " "
+				// picks without a service name must not move the api rotation
+				b.Pick(mixed, "")
+				b.Pick(mixed, "")
+			}
+			if seq != "api-1 api-2 api-1 api-2 " {
+				t.Errorf("api picks = %s, want a strict rotation", seq)
+			}
+		})
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"api"}, "api"},
+		{[]string{"api", "api"}, "api"},
+		{[]string{"db", "api", "db", "cache"}, "api,cache,db"},
+	}
+	for _, tt := range tests {
+		list := make([]Instance, len(tt.names))
+		for i, name := range tt.names {
+			list[i] = Instance{Service: &pb.Service{Id: fmt.Sprint(i), Name: name}}
+		}
+		if got := groupKey(list); got != tt.want {
+			t.Errorf("groupKey(%v) = %s, want %s", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestConsistentHashCachesRing(t *testing.T) {
+	b := NewConsistentHash(defaultReplicas)
+	list := instances(map[string]int{"a": 1, "b": 1, "c": 1})
+
+	b.Pick(list, "k1")
+	ring := b.rings["svc"]
+	b.Pick([]Instance{list[2], list[0], list[1]}, "k2")
+	if b.rings["svc"] != ring {
+		t.Error("ring rebuilt although membership did not change")
+	}
+
+	b.Pick(list[:2], "k3")
+	if b.rings["svc"] == ring || len(b.rings["svc"].nodes) != 2*defaultReplicas {
+		t.Error("ring not rebuilt after an instance left")
+	}
+}
+
+func BenchmarkConsistentHashPick(b *testing.B) {
+	weights := make(map[string]int)
+	for i := 0; i < 50; i++ {
+		weights[fmt.Sprintf("svc-%d", i)] = 1
+	}
+	list := instances(weights)
+	ch := NewConsistentHash(defaultReplicas)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch.Pick(list, strconv.Itoa(i))
+	}
+}
@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/errors"
+)
+
+// virtual nodes per instance; more replicas give a more even spread
+const defaultReplicas = 100
+
+// ConsistentHash maps a request key onto a hash ring of instances so the
+// same key keeps hitting the same instance while the set is stable, and
+// only about 1/n of keys move when an instance joins or leaves.
+// The ring of each group of services is kept until its membership changes.
+type ConsistentHash struct {
+	replicas int
+
+	mu    sync.Mutex
+	rings map[string]*hashRing // group key -> ring
+}
+
+// hashRing holds virtual nodes pointing into the instances sorted by ID
+type hashRing struct {
+	members string // instance IDs the ring was built from
+	nodes   []ringNode
+}
+
+type ringNode struct {
+	hash  uint32
+	index int
+}
+
+func NewConsistentHash(replicas int) *ConsistentHash {
+	if replicas <= 0 {
+		replicas = defaultReplicas
+	}
+	return &ConsistentHash{replicas: replicas, rings: make(map[string]*hashRing)}
+}
+
+func (b *ConsistentHash) Name() string { return StrategyConsistentHash }
+
+func (b *ConsistentHash) Pick(instances []Instance, key string) (*pb.Service, error) {
+	if len(instances) == 0 {
+		return nil, errNoInstances()
+	}
+	if key == "" {
+		return nil, errors.New(errors.CodeInvalidArgument,
+			"consistent_hash strategy requires a hash key")
+	}
+
+	sorted := sortedByID(instances)
+	ring := b.ring(sorted)
+
+	h := hashKey(key)
+	i := sort.Search(len(ring.nodes), func(i int) bool { return ring.nodes[i].hash >= h })
+	if i == len(ring.nodes) {
+		i = 0
+	}
+
+	return sorted[ring.nodes[i].index].Service, nil
+}
+
+// ring returns the cached ring for the instances, rebuilding it when an
+// instance joined or left
+func (b *ConsistentHash) ring(sorted []Instance) *hashRing {
+	ids := make([]string, len(sorted))
+	for i, inst := range sorted {
+		ids[i] = inst.Service.Id
+	}
+	members := strings.Join(ids, "\x00")
+	group := groupKey(sorted)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ring, ok := b.rings[group]; ok && ring.members == members {
+		return ring
+	}
+
+	ring := &hashRing{members: members, nodes: make([]ringNode, 0, len(sorted)*b.replicas)}
+	for i, id := range ids {
+		for r := 0; r < b.replicas; r++ {
+			ring.nodes = append(ring.nodes, ringNode{
+				hash:  hashKey(id + "#" + strconv.Itoa(r)),
+				index: i,
+			})
+		}
+	}
+	sort.Slice(ring.nodes, func(i, j int) bool { return ring.nodes[i].hash < ring.nodes[j].hash })
+
+	b.rings[group] = ring
+	return ring
+}
+
+func hashKey(key string) uint32 {
+	sum := md5.Sum([]byte(key))
+	return binary.BigEndian.Uint32(sum[:4])
+}
@@ -0,0 +1,340 @@
+package registry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/logger"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	snapshotFile = "snapshot.json"
+	walFile      = "wal.log"
+
+	defaultSnapshotThreshold = 1000
+)
+
+// FileStore is a durable store made of a snapshot plus a write-ahead log.
+// Every change is appended to the log before it becomes visible; once the
+// log holds SnapshotThreshold records the full state is written to a new
+// snapshot and the log is truncated. On open the snapshot is loaded and
+// the log replayed on top of it.
+type FileStore struct {
+	mu  sync.Mutex // serializes writes so log order matches memory order
+	mem *MemoryStore
+
+	dir               string
+	wal               *os.File
+	walRecords        int
+	snapshotThreshold int
+	syncWrites        bool
+}
+
+type walRecord struct {
+	Op      string          `json:"op"`
+	ID      string          `json:"id,omitempty"`
+	Service json.RawMessage `json:"service,omitempty"`
+}
+
+type snapshotData struct {
+	Services []json.RawMessage `json:"services"`
+}
+
+const (
+	opPut    = "put"
+	opDelete = "delete"
+)
+
+// OpenFileStore opens (or creates) a file store in cfg.Path
+func OpenFileStore(cfg config.StoreConfig) (*FileStore, error) {
+	if cfg.Path == "" {
+		return nil, errors.New(errors.CodeConfigError, "file store requires a path")
+	}
+	if err := os.MkdirAll(cfg.Path, 0o755); err != nil {
+		return nil, errors.Wrapf(err, errors.CodeConfigError, "cannot create store directory %s", cfg.Path)
+	}
+
+	fs := &FileStore{
+		mem:               NewMemoryStore(),
+		dir:               cfg.Path,
+		snapshotThreshold: cfg.SnapshotThreshold,
+		syncWrites:        cfg.SyncWrites,
+	}
+	if fs.snapshotThreshold <= 0 {
+		fs.snapshotThreshold = defaultSnapshotThreshold
+	}
+
+	if err := fs.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := fs.replayWAL(); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+func (fs *FileStore) Put(svc *pb.Service) error {
+	if svc == nil || svc.Id == "" {
+		return errors.New(errors.CodeInvalidArgument, "service with an ID is required")
+	}
+
+	data, err := protojson.Marshal(svc)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternalError, "cannot encode service")
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := fs.appendWAL(walRecord{Op: opPut, Service: data}); err != nil {
+		return err
+	}
+
+	fs.mem.mu.Lock()
+	fs.mem.put(svc)
+	fs.mem.mu.Unlock()
+
+	return fs.maybeSnapshot()
+}
+
+func (fs *FileStore) Get(id string) (*pb.Service, error) {
+	return fs.mem.Get(id)
+}
+
+func (fs *FileStore) List() ([]*pb.Service, error) {
+	return fs.mem.List()
+}
+
+func (fs *FileStore) Delete(id string) (*pb.Service, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, err := fs.mem.Get(id); err != nil {
+		return nil, err
+	}
+
+	if err := fs.appendWAL(walRecord{Op: opDelete, ID: id}); err != nil {
+		return nil, err
+	}
+
+	fs.mem.mu.Lock()
+	svc, _ := fs.mem.delete(id)
+	fs.mem.mu.Unlock()
+
+	return svc, fs.maybeSnapshot()
+}
+
+func (fs *FileStore) Watch(ctx context.Context) ([]*pb.Service, <-chan StoreEvent, error) {
+	return fs.mem.Watch(ctx)
+}
+
+// Close writes a final snapshot so the next open has no log to replay
+func (fs *FileStore) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	err := fs.snapshot()
+	if fs.wal != nil {
+		if cerr := fs.wal.Close(); err == nil {
+			err = cerr
+		}
+		fs.wal = nil
+	}
+	fs.mem.Close()
+	return err
+}
+
+func (fs *FileStore) appendWAL(rec walRecord) error {
+	if fs.wal == nil {
+		return errors.New(errors.CodeServiceUnavailable, "registry store is closed")
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternalError, "cannot encode log record")
+	}
+	line = append(line, '\n')
+
+	if _, err := fs.wal.Write(line); err != nil {
+		return errors.Wrap(err, errors.CodeInternalError, "cannot write registry log")
+	}
+	if fs.syncWrites {
+		if err := fs.wal.Sync(); err != nil {
+			return errors.Wrap(err, errors.CodeInternalError, "cannot sync registry log")
+		}
+	}
+
+	fs.walRecords++
+	return nil
+}
+
+func (fs *FileStore) maybeSnapshot() error {
+	if fs.walRecords < fs.snapshotThreshold {
+		return nil
+	}
+	return fs.snapshot()
+}
+
+// snapshot writes the current state atomically and truncates the log.
+// A crash between the two steps is harmless: replaying the old log over
+// the new snapshot yields the same state.
+func (fs *FileStore) snapshot() error {
+	services, _ := fs.mem.List()
+
+	data := snapshotData{Services: make([]json.RawMessage, 0, len(services))}
+	for _, svc := range services {
+		raw, err := protojson.Marshal(svc)
+		if err != nil {
+			return errors.Wrap(err, errors.CodeInternalError, "cannot encode service")
+		}
+		data.Services = append(data.Services, raw)
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternalError, "cannot encode snapshot")
+	}
+
+	path := filepath.Join(fs.dir, snapshotFile)
+	tmp := path + ".tmp"
+	if err := writeFileSync(tmp, content); err != nil {
+		return errors.Wrap(err, errors.CodeInternalError, "cannot write snapshot")
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Wrap(err, errors.CodeInternalError, "cannot replace snapshot")
+	}
+
+	if fs.wal != nil {
+		if err := fs.wal.Truncate(0); err != nil {
+			return errors.Wrap(err, errors.CodeInternalError, "cannot truncate registry log")
+		}
+		if _, err := fs.wal.Seek(0, io.SeekStart); err != nil {
+			return errors.Wrap(err, errors.CodeInternalError, "cannot rewind registry log")
+		}
+	}
+	fs.walRecords = 0
+
+	return nil
+}
+
+func (fs *FileStore) loadSnapshot() error {
+	content, err := os.ReadFile(filepath.Join(fs.dir, snapshotFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternalError, "cannot read snapshot")
+	}
+
+	var data snapshotData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return errors.Wrap(err, errors.CodeInternalError, "corrupt registry snapshot")
+	}
+
+	for _, raw := range data.Services {
+		svc := &pb.Service{}
+		if err := protojson.Unmarshal(raw, svc); err != nil {
+			return errors.Wrap(err, errors.CodeInternalError, "corrupt service in registry snapshot")
+		}
+		fs.mem.services[svc.Id] = svc
+	}
+
+	return nil
+}
+
+// replayWAL applies the log and opens it for appending. Every record ends in
+// a newline, so only a final line without one is a torn write from a crash; it
+// is dropped and the log truncated there. Any other damaged record fails the
+// open rather than discard the records that follow it.
+func (fs *FileStore) replayWAL() error {
+	path := filepath.Join(fs.dir, walFile)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternalError, "cannot open registry log")
+	}
+
+	reader := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) == 0 {
+				break
+			}
+			logger.Default().Warn("truncating torn record at the end of the registry log",
+				logger.FieldString("path", path),
+				logger.FieldInt64("offset", offset),
+			)
+			if err := f.Truncate(offset); err != nil {
+				f.Close()
+				return errors.Wrap(err, errors.CodeInternalError, "cannot truncate registry log")
+			}
+			break
+		}
+		if err != nil {
+			f.Close()
+			return errors.Wrap(err, errors.CodeInternalError, "cannot read registry log")
+		}
+
+		var rec walRecord
+		if json.Unmarshal(line, &rec) != nil || !fs.apply(rec) {
+			f.Close()
+			return errors.Newf(errors.CodeInternalError,
+				"corrupt record in registry log %s at offset %d", path, offset)
+		}
+
+		offset += int64(len(line))
+		fs.walRecords++
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return errors.Wrap(err, errors.CodeInternalError, "cannot seek registry log")
+	}
+
+	fs.wal = f
+	return nil
+}
+
+func (fs *FileStore) apply(rec walRecord) bool {
+	switch rec.Op {
+	case opPut:
+		svc := &pb.Service{}
+		if err := protojson.Unmarshal(rec.Service, svc); err != nil || svc.Id == "" {
+			return false
+		}
+		fs.mem.services[svc.Id] = svc
+	case opDelete:
+		delete(fs.mem.services, rec.ID)
+	default:
+		return false
+	}
+	return true
+}
+
+func writeFileSync(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,224 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+)
+
+func openTestStore(t *testing.T, dir string, threshold int) *FileStore {
+	t.Helper()
+
+	fs, err := OpenFileStore(config.StoreConfig{Type: "file", Path: dir, SnapshotThreshold: threshold})
+	if err != nil {
+		t.Fatalf("OpenFileStore: %v", err)
+	}
+	return fs
+}
+
+func storedIDs(t *testing.T, fs *FileStore) []string {
+	t.Helper()
+
+	services, err := fs.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	ids := make([]string, 0, len(services))
+	for _, svc := range services {
+		ids = append(ids, svc.Id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func equalIDs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// crash leaves the log as is, unlike Close which snapshots it away
+func crash(fs *FileStore) {
+	fs.wal.Close()
+}
+
+func TestFileStoreReplaysWAL(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir, 100)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := fs.Put(&pb.Service{Id: id, Name: "svc", Port: 80}); err != nil {
+			t.Fatalf("Put %s: %v", id, err)
+		}
+	}
+	if err := fs.Put(&pb.Service{Id: "b", Name: "svc", Port: 81}); err != nil {
+		t.Fatalf("Put b: %v", err)
+	}
+	if _, err := fs.Delete("a"); err != nil {
+		t.Fatalf("Delete a: %v", err)
+	}
+	crash(fs)
+
+	if _, err := os.Stat(filepath.Join(dir, snapshotFile)); !os.IsNotExist(err) {
+		t.Fatalf("snapshot written below threshold: %v", err)
+	}
+
+	reopened := openTestStore(t, dir, 100)
+	defer reopened.Close()
+
+	if got, want := storedIDs(t, reopened), []string{"b", "c"}; !equalIDs(got, want) {
+		t.Fatalf("replayed IDs = %v, want %v", got, want)
+	}
+	svc, err := reopened.Get("b")
+	if err != nil {
+		t.Fatalf("Get b: %v", err)
+	}
+	if svc.Port != 81 {
+		t.Errorf("b port = %d, want the last written 81", svc.Port)
+	}
+	if reopened.walRecords != 5 {
+		t.Errorf("walRecords = %d, want 5", reopened.walRecords)
+	}
+}
+
+// writeTestLog puts a and b, crashes and returns the log path and contents
+func writeTestLog(t *testing.T, dir string) (string, []byte) {
+	t.Helper()
+
+	fs := openTestStore(t, dir, 100)
+	for _, id := range []string{"a", "b"} {
+		if err := fs.Put(&pb.Service{Id: id, Name: "svc"}); err != nil {
+			t.Fatalf("Put %s: %v", id, err)
+		}
+	}
+	crash(fs)
+
+	path := filepath.Join(dir, walFile)
+	good, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	return path, good
+}
+
+func TestFileStoreTruncatesTornRecord(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string
+	}{
+		{"partial line", `{"op":"put","service":{"id":"x"`},
+		{"complete json without newline", `{"op":"delete","id":"a"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path, good := writeTestLog(t, dir)
+			if err := os.WriteFile(path, append(good, tt.tail...), 0o644); err != nil {
+				t.Fatalf("write log: %v", err)
+			}
+
+			reopened := openTestStore(t, dir, 100)
+			if got, want := storedIDs(t, reopened), []string{"a", "b"}; !equalIDs(got, want) {
+				t.Fatalf("IDs = %v, want %v", got, want)
+			}
+
+			// the log is cut at the torn record and new records follow the good ones
+			if err := reopened.Put(&pb.Service{Id: "c", Name: "svc"}); err != nil {
+				t.Fatalf("Put c: %v", err)
+			}
+			crash(reopened)
+
+			again := openTestStore(t, dir, 100)
+			defer again.Close()
+			if got, want := storedIDs(t, again), []string{"a", "b", "c"}; !equalIDs(got, want) {
+				t.Fatalf("IDs after append = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFileStoreRejectsCorruptRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record string
+	}{
+		{"invalid json", "not json\n"},
+		{"unknown op", `{"op":"rename","id":"a"}` + "\n"},
+		{"put without id", `{"op":"put","service":{"name":"svc"}}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path, good := writeTestLog(t, dir)
+
+			// the corrupt record sits between good ones, so it is not a torn write
+			damaged := append(append([]byte{}, good...), tt.record...)
+			damaged = append(damaged, `{"op":"delete","id":"a"}`+"\n"...)
+			if err := os.WriteFile(path, damaged, 0o644); err != nil {
+				t.Fatalf("write log: %v", err)
+			}
+
+			_, err := OpenFileStore(config.StoreConfig{Type: "file", Path: dir, SnapshotThreshold: 100})
+			if !errors.Is(err, errors.CodeInternalError) {
+				t.Fatalf("OpenFileStore err = %v, want InternalError", err)
+			}
+
+			after, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read log: %v", err)
+			}
+			if string(after) != string(damaged) {
+				t.Errorf("log changed by a failed open:\n%s\nwant:\n%s", after, damaged)
+			}
+		})
+	}
+}
+
+func TestFileStoreSnapshotTruncatesWAL(t *testing.T) {
+	dir := t.TempDir()
+
+	fs := openTestStore(t, dir, 3)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if err := fs.Put(&pb.Service{Id: id, Name: "svc"}); err != nil {
+			t.Fatalf("Put %s: %v", id, err)
+		}
+	}
+	crash(fs)
+
+	if _, err := os.Stat(filepath.Join(dir, snapshotFile)); err != nil {
+		t.Fatalf("no snapshot after reaching the threshold: %v", err)
+	}
+	if fs.walRecords != 1 {
+		t.Errorf("walRecords = %d, want 1 after the snapshot", fs.walRecords)
+	}
+
+	reopened := openTestStore(t, dir, 3)
+	if got, want := storedIDs(t, reopened), []string{"a", "b", "c", "d"}; !equalIDs(got, want) {
+		t.Fatalf("IDs = %v, want %v", got, want)
+	}
+	if err := reopened.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, walFile))
+	if err != nil {
+		t.Fatalf("stat log: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("log is %d bytes after Close, want empty", info.Size())
+	}
+	if err := reopened.Put(&pb.Service{Id: "e"}); err == nil {
+		t.Error("Put succeeded on a closed store")
+	}
+}
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	pb "upm-simple/internal"
+)
+
+// Filter selects services by name, tags, version and health
+type Filter struct {
+	Name    string
+	Tags    []string
+	Version *VersionConstraint
+	Health  []pb.HealthStatus
+}
+
+// NewFilter builds a filter from a Discover request
+func NewFilter(req *pb.DiscoverRequest) (*Filter, error) {
+	version, err := ParseVersionConstraint(req.VersionConstraint)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Filter{
+		Name:    req.ServiceName,
+		Tags:    req.Tags,
+		Version: version,
+		Health:  req.Health,
+	}, nil
+}
+
+// Match reports whether svc passes every criterion of the filter.
+// Empty criteria match everything.
+func (f *Filter) Match(svc *pb.Service) bool {
+	if f.Name != "" && svc.Name != f.Name {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !hasTag(svc, tag) {
+			return false
+		}
+	}
+
+	if f.Version != nil && !f.Version.Match(svc.Version) {
+		return false
+	}
+
+	if len(f.Health) > 0 {
+		found := false
+		for _, h := range f.Health {
+			if svc.Health == h {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsRoutable reports whether an instance should receive new traffic.
+// Instances that never reported health are assumed to be up.
+func IsRoutable(svc *pb.Service) bool {
+	return svc.Health != pb.HealthStatus_HEALTH_STATUS_UNHEALTHY &&
+		svc.Health != pb.HealthStatus_HEALTH_STATUS_DRAINING
+}
+
+func hasTag(svc *pb.Service, tag string) bool {
+	for _, t := range svc.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"testing"
+
+	pb "upm-simple/internal"
+)
+
+func TestFilterMatch(t *testing.T) {
+	svc := &pb.Service{
+		Name:    "api",
+		Tags:    []string{"http", "canary"},
+		Version: "1.4.2",
+		Health:  pb.HealthStatus_HEALTH_STATUS_HEALTHY,
+	}
+
+	tests := []struct {
+		name string
+		req  *pb.DiscoverRequest
+		want bool
+	}{
+		{"empty filter", &pb.DiscoverRequest{}, true},
+		{"name", &pb.DiscoverRequest{ServiceName: "api"}, true},
+		{"other name", &pb.DiscoverRequest{ServiceName: "db"}, false},
+		{"all tags", &pb.DiscoverRequest{Tags: []string{"canary", "http"}}, true},
+		{"missing tag", &pb.DiscoverRequest{Tags: []string{"http", "grpc"}}, false},
+		{"version in range", &pb.DiscoverRequest{VersionConstraint: ">=1.2 <2"}, true},
+		{"version out of range", &pb.DiscoverRequest{VersionConstraint: ">=1.5"}, false},
+		{"health listed", &pb.DiscoverRequest{Health: []pb.HealthStatus{
+			pb.HealthStatus_HEALTH_STATUS_DRAINING, pb.HealthStatus_HEALTH_STATUS_HEALTHY,
+		}}, true},
+		{"health not listed", &pb.DiscoverRequest{Health: []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_UNHEALTHY}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFilter(tt.req)
+			if err != nil {
+				t.Fatalf("NewFilter: %v", err)
+			}
+			if got := f.Match(svc); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewFilter(&pb.DiscoverRequest{VersionConstraint: ">=x"}); err == nil {
+		t.Error("NewFilter accepted an invalid version constraint")
+	}
+}
+
+func TestIsRoutable(t *testing.T) {
+	routable := map[pb.HealthStatus]bool{
+		pb.HealthStatus_HEALTH_STATUS_UNKNOWN:   true,
+		pb.HealthStatus_HEALTH_STATUS_HEALTHY:   true,
+		pb.HealthStatus_HEALTH_STATUS_UNHEALTHY: false,
+		pb.HealthStatus_HEALTH_STATUS_DRAINING:  false,
+	}
+	for health, want := range routable {
+		if got := IsRoutable(&pb.Service{Health: health}); got != want {
+			t.Errorf("IsRoutable(%s) = %v, want %v", health, got, want)
+		}
+	}
+}
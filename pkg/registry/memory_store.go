@@ -0,0 +1,107 @@
+package registry
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/errors"
+)
+
+// MemoryStore keeps services in a map; state is lost on restart
+type MemoryStore struct {
+	mu       sync.RWMutex
+	services map[string]*pb.Service
+	hub      *watchHub
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		services: make(map[string]*pb.Service),
+		hub:      newWatchHub(),
+	}
+}
+
+func (m *MemoryStore) Put(svc *pb.Service) error {
+	if svc == nil || svc.Id == "" {
+		return errors.New(errors.CodeInvalidArgument, "service with an ID is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(svc)
+	return nil
+}
+
+func (m *MemoryStore) Get(id string) (*pb.Service, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	svc, ok := m.services[id]
+	if !ok {
+		return nil, errors.NotFoundError("service", id)
+	}
+	return svc, nil
+}
+
+func (m *MemoryStore) List() ([]*pb.Service, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.list(), nil
+}
+
+func (m *MemoryStore) Delete(id string) (*pb.Service, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	svc, ok := m.delete(id)
+	if !ok {
+		return nil, errors.NotFoundError("service", id)
+	}
+	return svc, nil
+}
+
+func (m *MemoryStore) Watch(ctx context.Context) ([]*pb.Service, <-chan StoreEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.list(), m.hub.subscribe(ctx), nil
+}
+
+func (m *MemoryStore) Close() error {
+	m.hub.close()
+	return nil
+}
+
+// put, delete and list expect the caller to hold m.mu
+
+func (m *MemoryStore) put(svc *pb.Service) {
+	eventType := EventAdded
+	if _, exists := m.services[svc.Id]; exists {
+		eventType = EventUpdated
+	}
+	m.services[svc.Id] = svc
+	m.hub.publish(StoreEvent{Type: eventType, Service: svc})
+}
+
+func (m *MemoryStore) delete(id string) (*pb.Service, bool) {
+	svc, ok := m.services[id]
+	if !ok {
+		return nil, false
+	}
+	delete(m.services, id)
+	m.hub.publish(StoreEvent{Type: EventDeleted, Service: svc})
+	return svc, true
+}
+
+func (m *MemoryStore) list() []*pb.Service {
+	services := make([]*pb.Service, 0, len(m.services))
+	for _, svc := range m.services {
+		services = append(services, svc)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Id < services[j].Id })
+	return services
+}
@@ -0,0 +1,257 @@
+package registry
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+// Scheme is the gRPC target scheme resolved through the registry,
+// e.g. "upm:///mock-engine" or "upm://mock-engine"
+const Scheme = "upm"
+
+const (
+	// used when the registry does not support Watch
+	defaultPollInterval = 10 * time.Second
+
+	minResolveBackoff = 500 * time.Millisecond
+	maxResolveBackoff = 30 * time.Second
+
+	// picks a healthy address per RPC and skips instances whose connection failed
+	roundRobinServiceConfig = `{"loadBalancingConfig":[{"round_robin":{}}]}`
+)
+
+// ResolverBuilder builds resolvers that turn a logical service name into the
+// addresses of its registered instances. Instances are tracked with the Watch
+// stream; registries without Watch are polled through Discover.
+type ResolverBuilder struct {
+	client       pb.ServiceRegistryClient
+	pollInterval time.Duration
+}
+
+// NewResolverBuilder creates a resolver builder backed by a registry client
+func NewResolverBuilder(client pb.ServiceRegistryClient) *ResolverBuilder {
+	return &ResolverBuilder{
+		client:       client,
+		pollInterval: defaultPollInterval,
+	}
+}
+
+// WithPollInterval sets how often Discover is polled when Watch is unavailable
+func (b *ResolverBuilder) WithPollInterval(interval time.Duration) *ResolverBuilder {
+	if interval > 0 {
+		b.pollInterval = interval
+	}
+	return b
+}
+
+func (b *ResolverBuilder) Scheme() string {
+	return Scheme
+}
+
+func (b *ResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	// upm://name puts the name in the authority, upm:///name in the path
+	name := target.URL.Host
+	if name == "" {
+		name = target.Endpoint()
+	}
+	if name == "" {
+		return nil, errors.Newf(errors.CodeInvalidArgument,
+			"missing service name in target '%s'", target.URL.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &registryResolver{
+		client:       b.client,
+		serviceName:  name,
+		pollInterval: b.pollInterval,
+		cc:           cc,
+		ctx:          ctx,
+		cancel:       cancel,
+		resolveNow:   make(chan struct{}, 1),
+	}
+
+	r.wg.Add(1)
+	go r.run()
+
+	return r, nil
+}
+
+// RegisterResolver makes the upm scheme available to every grpc.Dial in the process
+func RegisterResolver(client pb.ServiceRegistryClient) {
+	resolver.Register(NewResolverBuilder(client))
+}
+
+// DialOptions returns the options needed to dial "upm:///<service>" targets
+// with round-robin balancing across the resolved instances
+func DialOptions(client pb.ServiceRegistryClient) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithResolvers(NewResolverBuilder(client)),
+		grpc.WithDefaultServiceConfig(roundRobinServiceConfig),
+	}
+}
+
+type registryResolver struct {
+	client       pb.ServiceRegistryClient
+	serviceName  string
+	pollInterval time.Duration
+	cc           resolver.ClientConn
+
+	ctx        context.Context
+	cancel     context.CancelFunc
+	resolveNow chan struct{}
+	wg         sync.WaitGroup
+}
+
+func (r *registryResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (r *registryResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+func (r *registryResolver) run() {
+	defer r.wg.Done()
+
+	backoff := minResolveBackoff
+	for {
+		synced, err := r.watch()
+		if r.ctx.Err() != nil {
+			return
+		}
+
+		if status.Code(err) == codes.Unimplemented {
+			r.poll()
+			return
+		}
+
+		logger.Default().Warn("registry watch interrupted",
+			logger.FieldString("service", r.serviceName),
+			logger.FieldError(err),
+		)
+
+		// keep addresses fresh while the stream is down
+		if err := r.discover(); err != nil {
+			r.cc.ReportError(err)
+		}
+
+		if synced {
+			backoff = minResolveBackoff
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-time.After(backoff):
+		case <-r.resolveNow:
+		}
+
+		backoff *= 2
+		if backoff > maxResolveBackoff {
+			backoff = maxResolveBackoff
+		}
+	}
+}
+
+// watch follows the Watch stream and pushes the address set after the initial
+// snapshot and on every change. It reports whether the snapshot was received.
+func (r *registryResolver) watch() (bool, error) {
+	stream, err := r.client.Watch(r.ctx, &pb.WatchRequest{ServiceName: r.serviceName})
+	if err != nil {
+		return false, err
+	}
+
+	services := make(map[string]*pb.Service)
+	synced := false
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return synced, err
+		}
+
+		switch event.Type {
+		case pb.WatchEvent_ADDED, pb.WatchEvent_UPDATED:
+			services[event.Service.Id] = event.Service
+		case pb.WatchEvent_REMOVED:
+			delete(services, event.Service.Id)
+		case pb.WatchEvent_SYNCED:
+			synced = true
+		}
+
+		if synced {
+			r.updateState(services)
+		}
+	}
+}
+
+// poll refreshes the address set through Discover until the resolver is closed
+func (r *registryResolver) poll() {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.discover(); err != nil {
+			r.cc.ReportError(err)
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolveNow:
+		}
+	}
+}
+
+func (r *registryResolver) discover() error {
+	resp, err := r.client.Discover(r.ctx, &pb.DiscoverRequest{ServiceName: r.serviceName})
+	if err != nil {
+		return err
+	}
+
+	services := make(map[string]*pb.Service, len(resp.Services))
+	for _, svc := range resp.Services {
+		services[svc.Id] = svc
+	}
+	r.updateState(services)
+	return nil
+}
+
+func (r *registryResolver) updateState(services map[string]*pb.Service) {
+	addrs := make([]resolver.Address, 0, len(services))
+	for _, svc := range services {
+		if !IsRoutable(svc) {
+			continue
+		}
+		addrs = append(addrs, resolver.Address{
+			Addr: net.JoinHostPort(svc.Host, strconv.Itoa(int(svc.Port))),
+		})
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Addr < addrs[j].Addr })
+
+	// tell the balancer so it can back off and ask for re-resolution
+	if err := r.cc.UpdateState(resolver.State{Addresses: addrs}); err != nil {
+		logger.Default().Warn("resolver state rejected",
+			logger.FieldString("service", r.serviceName),
+			logger.FieldError(err),
+		)
+		r.cc.ReportError(err)
+	}
+}
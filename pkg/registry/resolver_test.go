@@ -0,0 +1,181 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/config"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+// testClientConn records what a resolver reports to gRPC
+type testClientConn struct {
+	resolver.ClientConn
+	states chan []string
+	errs   chan error
+	reject error
+}
+
+func newTestClientConn() *testClientConn {
+	return &testClientConn{states: make(chan []string, 16), errs: make(chan error, 16)}
+}
+
+func (c *testClientConn) UpdateState(state resolver.State) error {
+	addrs := make([]string, len(state.Addresses))
+	for i, a := range state.Addresses {
+		addrs[i] = a.Addr
+	}
+	c.states <- addrs
+	return c.reject
+}
+
+func (c *testClientConn) ReportError(err error) {
+	c.errs <- err
+}
+
+// waitState returns the first state equal to want, skipping earlier ones
+func (c *testClientConn) waitState(t *testing.T, want ...string) {
+	t.Helper()
+
+	deadline := time.After(2 * time.Second)
+	var last []string
+	for {
+		select {
+		case addrs := <-c.states:
+			if fmt.Sprint(addrs) == fmt.Sprint(want) {
+				return
+			}
+			last = addrs
+		case <-deadline:
+			t.Fatalf("resolver state = %v, want %v", last, want)
+		}
+	}
+}
+
+// noWatchClient is a registry client for servers without the Watch RPC
+type noWatchClient struct {
+	pb.ServiceRegistryClient
+}
+
+func (noWatchClient) Watch(context.Context, *pb.WatchRequest, ...grpc.CallOption) (pb.ServiceRegistry_WatchClient, error) {
+	return nil, status.Error(codes.Unimplemented, "unknown method Watch")
+}
+
+func startResolverRegistry(t *testing.T) (*Server, pb.ServiceRegistryClient) {
+	t.Helper()
+
+	s := newTestServer(t, config.RegistryConfig{})
+	gs := grpc.NewServer()
+	pb.RegisterServiceRegistryServer(gs, s)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return s, pb.NewServiceRegistryClient(conn)
+}
+
+func buildResolver(t *testing.T, b *ResolverBuilder, target string, cc resolver.ClientConn) resolver.Resolver {
+	t.Helper()
+
+	u, err := url.Parse(target)
+	if err != nil {
+		t.Fatalf("parse %s: %v", target, err)
+	}
+	r, err := b.Build(resolver.Target{URL: *u}, cc, resolver.BuildOptions{})
+	if err != nil {
+		t.Fatalf("Build %s: %v", target, err)
+	}
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestResolverTargets(t *testing.T) {
+	s, client := startResolverRegistry(t)
+	register(t, s, "api", "10.0.0.1", 8080)
+
+	for _, target := range []string{"upm:///api", "upm://api"} {
+		t.Run(target, func(t *testing.T) {
+			cc := newTestClientConn()
+			buildResolver(t, NewResolverBuilder(client), target, cc)
+			cc.waitState(t, "10.0.0.1:8080")
+		})
+	}
+
+	u, _ := url.Parse("upm:///")
+	if _, err := NewResolverBuilder(client).Build(resolver.Target{URL: *u}, newTestClientConn(), resolver.BuildOptions{}); err == nil {
+		t.Error("Build accepted a target without a service name")
+	}
+}
+
+func TestResolverFollowsWatch(t *testing.T) {
+	s, client := startResolverRegistry(t)
+	a := register(t, s, "api", "10.0.0.2", 8080)
+	register(t, s, "api", "10.0.0.1", 8080)
+	register(t, s, "db", "10.0.0.9", 5432)
+
+	cc := newTestClientConn()
+	buildResolver(t, NewResolverBuilder(client), "upm:///api", cc)
+	cc.waitState(t, "10.0.0.1:8080", "10.0.0.2:8080")
+
+	c := register(t, s, "api", "10.0.0.3", 8080)
+	cc.waitState(t, "10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.3:8080")
+
+	// draining instances stop receiving traffic
+	s.Heartbeat(context.Background(), &pb.HeartbeatRequest{ServiceId: c, Health: pb.HealthStatus_HEALTH_STATUS_DRAINING})
+	cc.waitState(t, "10.0.0.1:8080", "10.0.0.2:8080")
+
+	s.Deregister(context.Background(), &pb.DeregisterRequest{ServiceId: a})
+	cc.waitState(t, "10.0.0.1:8080")
+}
+
+func TestResolverPollsWithoutWatch(t *testing.T) {
+	s, client := startResolverRegistry(t)
+	register(t, s, "api", "10.0.0.1", 8080)
+
+	cc := newTestClientConn()
+	b := NewResolverBuilder(noWatchClient{client}).WithPollInterval(10 * time.Millisecond)
+	buildResolver(t, b, "upm:///api", cc)
+	cc.waitState(t, "10.0.0.1:8080")
+
+	register(t, s, "api", "10.0.0.2", 8080)
+	cc.waitState(t, "10.0.0.1:8080", "10.0.0.2:8080")
+}
+
+func TestResolverReportsRejectedState(t *testing.T) {
+	s, client := startResolverRegistry(t)
+	register(t, s, "api", "10.0.0.1", 8080)
+
+	cc := newTestClientConn()
+	cc.reject = fmt.Errorf("bad resolver state")
+	buildResolver(t, NewResolverBuilder(client), "upm:///api", cc)
+
+	select {
+	case err := <-cc.errs:
+		if !strings.Contains(err.Error(), "bad resolver state") {
+			t.Errorf("reported %v, want the balancer's error", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("rejected state was not reported")
+	}
+}
@@ -0,0 +1,383 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/logger"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultHeartbeatInterval = 30 * time.Second
+	defaultHeartbeatTimeout  = 90 * time.Second
+)
+
+// liveness is the in-memory heartbeat state of a stored service. It is not
+// persisted: after a restart every stored service gets a full heartbeat
+// timeout to check in again.
+type liveness struct {
+	lastSeen          time.Time
+	activeConnections int32
+}
+
+// Server implements the ServiceRegistry gRPC service
+type Server struct {
+	pb.UnimplementedServiceRegistryServer
+	store    Store
+	balancer Balancer
+	log      logger.Logger
+
+	// mu guards liveness and makes read-modify-write sequences on the store atomic
+	mu       sync.Mutex
+	liveness map[string]*liveness
+
+	heartbeatInterval time.Duration
+	heartbeatTimeout  time.Duration
+
+	// closed by Shutdown to end open Watch streams
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// NewServer creates the ServiceRegistry gRPC service on top of a store.
+// Call RunReaper to evict services that stop heartbeating.
+func NewServer(cfg config.RegistryConfig, store Store, log logger.Logger) (*Server, error) {
+	balancer, err := NewBalancer(cfg.LoadBalancingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		store:             store,
+		balancer:          balancer,
+		log:               log,
+		liveness:          make(map[string]*liveness),
+		heartbeatInterval: cfg.HeartbeatInterval,
+		heartbeatTimeout:  cfg.HeartbeatTimeout,
+		done:              make(chan struct{}),
+	}
+	if s.heartbeatInterval <= 0 {
+		s.heartbeatInterval = defaultHeartbeatInterval
+	}
+	if s.heartbeatTimeout <= 0 {
+		s.heartbeatTimeout = defaultHeartbeatTimeout
+	}
+
+	// services restored from a durable store start a fresh heartbeat window
+	services, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, svc := range services {
+		s.liveness[svc.Id] = &liveness{lastSeen: now}
+	}
+
+	return s, nil
+}
+
+func (s *Server) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	if err := validateService(req.Service); err != nil {
+		return nil, err
+	}
+
+	id := fmt.Sprintf("%s-%s-%d", req.Service.Name, req.Service.Host, req.Service.Port)
+	req.Service.Id = id
+
+	s.mu.Lock()
+	err := s.store.Put(req.Service)
+	if err == nil {
+		s.liveness[id] = &liveness{lastSeen: time.Now()}
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("service registered",
+		logger.FieldString("service_id", id),
+		logger.FieldString("name", req.Service.Name),
+		logger.FieldString("address", net.JoinHostPort(req.Service.Host, strconv.Itoa(int(req.Service.Port)))),
+	)
+
+	return &pb.RegisterResponse{
+		Response: &pb.CommonResponse{
+			Success: true,
+			Message: "Registered",
+		},
+		ServiceId: id,
+	}, nil
+}
+
+// validateService checks the fields the service ID is built from
+func validateService(svc *pb.Service) error {
+	switch {
+	case svc == nil:
+		return errors.ValidationError("service", "is required")
+	case svc.Name == "":
+		return errors.ValidationError("service.name", "is required")
+	case svc.Host == "":
+		return errors.ValidationError("service.host", "is required")
+	case svc.Port <= 0 || svc.Port > 65535:
+		return errors.ValidationError("service.port",
+			fmt.Sprintf("must be between 1 and 65535, got %d", svc.Port))
+	}
+	return nil
+}
+
+// Deregister removes a service by the ID returned from Register
+func (s *Server) Deregister(ctx context.Context, req *pb.DeregisterRequest) (*pb.DeregisterResponse, error) {
+	if req.ServiceId == "" {
+		return nil, errors.ValidationError("service_id", "is required")
+	}
+
+	s.mu.Lock()
+	svc, err := s.store.Delete(req.ServiceId)
+	if err == nil {
+		delete(s.liveness, req.ServiceId)
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("service deregistered",
+		logger.FieldString("service_id", req.ServiceId),
+		logger.FieldString("name", svc.Name),
+	)
+
+	return &pb.DeregisterResponse{
+		Response: &pb.CommonResponse{
+			Success: true,
+			Message: "Deregistered",
+		},
+	}, nil
+}
+
+func (s *Server) Discover(ctx context.Context, req *pb.DiscoverRequest) (*pb.DiscoverResponse, error) {
+	filter, err := NewFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	var found []*pb.Service
+	var instances []Instance
+	for _, svc := range services {
+		if !filter.Match(svc) {
+			continue
+		}
+		found = append(found, svc)
+
+		// unless health was asked for explicitly, only route to instances taking traffic
+		if len(req.Health) == 0 && !IsRoutable(svc) {
+			continue
+		}
+		var active int32
+		if l, ok := s.liveness[svc.Id]; ok {
+			active = l.activeConnections
+		}
+		instances = append(instances, Instance{
+			Service:           svc,
+			Weight:            int(svc.Weight),
+			ActiveConnections: int(active),
+		})
+	}
+	s.mu.Unlock()
+
+	if req.PickOne {
+		picked, err := s.balancer.Pick(instances, req.HashKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return &pb.DiscoverResponse{
+			Response: &pb.CommonResponse{
+				Success: true,
+				Message: fmt.Sprintf("Picked %s using %s", picked.Id, s.balancer.Name()),
+			},
+			Services: []*pb.Service{picked},
+		}, nil
+	}
+
+	return &pb.DiscoverResponse{
+		Response: &pb.CommonResponse{
+			Success: true,
+			Message: fmt.Sprintf("Found %d services", len(found)),
+		},
+		Services: found,
+	}, nil
+}
+
+// Heartbeat refreshes the last-seen timestamp of a registered service.
+// Unknown IDs (e.g. already evicted) return NotFound so the caller re-registers.
+func (s *Server) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
+	if req.ServiceId == "" {
+		return nil, errors.ValidationError("service_id", "is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svc, err := s.store.Get(req.ServiceId)
+	if err != nil {
+		return nil, err
+	}
+
+	s.liveness[req.ServiceId] = &liveness{
+		lastSeen:          time.Now(),
+		activeConnections: req.ActiveConnections,
+	}
+
+	if req.Health != pb.HealthStatus_HEALTH_STATUS_UNKNOWN && req.Health != svc.Health {
+		// stored services are shared with in-flight responses, so replace instead of mutating
+		updated := proto.Clone(svc).(*pb.Service)
+		updated.Health = req.Health
+		if err := s.store.Put(updated); err != nil {
+			return nil, err
+		}
+	}
+
+	return &pb.HeartbeatResponse{
+		Response: &pb.CommonResponse{
+			Success: true,
+			Message: "OK",
+		},
+		HeartbeatIntervalMs: s.heartbeatInterval.Milliseconds(),
+	}, nil
+}
+
+// Watch streams the current matching services followed by every change to them
+func (s *Server) Watch(req *pb.WatchRequest, stream pb.ServiceRegistry_WatchServer) error {
+	matches := func(svc *pb.Service) bool {
+		return req.ServiceName == "" || req.ServiceName == svc.Name
+	}
+
+	snapshot, events, err := s.store.Watch(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	for _, svc := range snapshot {
+		if !matches(svc) {
+			continue
+		}
+		if err := stream.Send(&pb.WatchEvent{Type: pb.WatchEvent_ADDED, Service: svc}); err != nil {
+			return err
+		}
+	}
+	if err := stream.Send(&pb.WatchEvent{Type: pb.WatchEvent_SYNCED}); err != nil {
+		return err
+	}
+
+	for {
+		var event StoreEvent
+		var ok bool
+		select {
+		case <-s.done:
+			return errors.New(errors.CodeServiceUnavailable, "registry is shutting down")
+		case event, ok = <-events:
+		}
+		if !ok {
+			break
+		}
+
+		if !matches(event.Service) {
+			continue
+		}
+		if err := stream.Send(&pb.WatchEvent{Type: toWatchEventType(event.Type), Service: event.Service}); err != nil {
+			return err
+		}
+	}
+
+	// the store closes the channel when the stream ends or the watcher falls behind
+	if err := stream.Context().Err(); err != nil {
+		return err
+	}
+	return errors.New(errors.CodeServiceUnavailable, "watcher fell behind, re-watch to resync")
+}
+
+func toWatchEventType(t StoreEventType) pb.WatchEvent_Type {
+	switch t {
+	case EventAdded:
+		return pb.WatchEvent_ADDED
+	case EventUpdated:
+		return pb.WatchEvent_UPDATED
+	case EventDeleted:
+		return pb.WatchEvent_REMOVED
+	default:
+		return pb.WatchEvent_UNKNOWN
+	}
+}
+
+// evictExpired removes every service whose last heartbeat is older than the timeout
+func (s *Server) evictExpired(now time.Time) []*pb.Service {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var evicted []*pb.Service
+	for id, l := range s.liveness {
+		if now.Sub(l.lastSeen) <= s.heartbeatTimeout {
+			continue
+		}
+
+		svc, err := s.store.Delete(id)
+		if err != nil && !errors.Is(err, errors.CodeNotFound) {
+			s.log.Error("eviction failed", logger.FieldString("service_id", id), logger.FieldError(err))
+			continue
+		}
+		delete(s.liveness, id)
+		if svc != nil {
+			evicted = append(evicted, svc)
+		}
+	}
+	return evicted
+}
+
+// Shutdown ends every open Watch stream so that a graceful stop of the gRPC
+// server does not wait for watchers. Call it before GracefulStop.
+func (s *Server) Shutdown() {
+	s.doneOnce.Do(func() { close(s.done) })
+}
+
+// RunReaper periodically evicts expired services until ctx is cancelled
+func (s *Server) RunReaper(ctx context.Context) {
+	ticker := time.NewTicker(s.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, svc := range s.evictExpired(now) {
+				s.log.Warn("service evicted",
+					logger.FieldString("service_id", svc.Id),
+					logger.FieldString("name", svc.Name),
+					logger.FieldAny("heartbeat_timeout", s.heartbeatTimeout),
+				)
+			}
+		}
+	}
+}
+
+// Balancer returns the strategy used for PickOne discovery
+func (s *Server) Balancer() Balancer {
+	return s.balancer
+}
@@ -0,0 +1,257 @@
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/logger"
+)
+
+func newTestServer(t *testing.T, cfg config.RegistryConfig) *Server {
+	t.Helper()
+
+	s, err := NewServer(cfg, NewMemoryStore(), &logger.NoopLogger{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return s
+}
+
+func register(t *testing.T, s *Server, name, host string, port int32) string {
+	t.Helper()
+
+	resp, err := s.Register(context.Background(), &pb.RegisterRequest{
+		Service: &pb.Service{Name: name, Host: host, Port: port},
+	})
+	if err != nil {
+		t.Fatalf("Register %s: %v", name, err)
+	}
+	return resp.ServiceId
+}
+
+func TestRegisterValidation(t *testing.T) {
+	s := newTestServer(t, config.RegistryConfig{})
+
+	tests := []struct {
+		name    string
+		service *pb.Service
+		field   string
+	}{
+		{"missing service", nil, "service"},
+		{"empty service", &pb.Service{}, "service.name"},
+		{"missing host", &pb.Service{Name: "api", Port: 80}, "service.host"},
+		{"zero port", &pb.Service{Name: "api", Host: "10.0.0.1"}, "service.port"},
+		{"negative port", &pb.Service{Name: "api", Host: "10.0.0.1", Port: -1}, "service.port"},
+		{"port too large", &pb.Service{Name: "api", Host: "10.0.0.1", Port: 65536}, "service.port"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Register(context.Background(), &pb.RegisterRequest{Service: tt.service})
+			if !errors.Is(err, errors.CodeValidation) {
+				t.Fatalf("err = %v, want a validation error", err)
+			}
+			if field, _ := err.(*errors.Error).GetMetadata("field"); field != tt.field {
+				t.Errorf("field = %v, want %s", field, tt.field)
+			}
+		})
+	}
+
+	services, _ := s.store.List()
+	if len(services) != 0 {
+		t.Errorf("%d services stored after rejected registrations", len(services))
+	}
+}
+
+func TestRegisterAssignsID(t *testing.T) {
+	s := newTestServer(t, config.RegistryConfig{})
+
+	id := register(t, s, "api", "10.0.0.1", 8080)
+	if id != "api-10.0.0.1-8080" {
+		t.Errorf("id = %s, want api-10.0.0.1-8080", id)
+	}
+	if again := register(t, s, "api", "10.0.0.1", 8080); again != id {
+		t.Errorf("re-registering gave id %s, want %s", again, id)
+	}
+
+	services, _ := s.store.List()
+	if len(services) != 1 {
+		t.Errorf("%d services stored, want 1", len(services))
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	s := newTestServer(t, config.RegistryConfig{HeartbeatInterval: 5 * time.Second})
+	id := register(t, s, "api", "10.0.0.1", 8080)
+
+	s.liveness[id].lastSeen = time.Now().Add(-time.Minute)
+	resp, err := s.Heartbeat(context.Background(), &pb.HeartbeatRequest{ServiceId: id, ActiveConnections: 7})
+	if err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if resp.HeartbeatIntervalMs != 5000 {
+		t.Errorf("interval = %dms, want 5000", resp.HeartbeatIntervalMs)
+	}
+	if l := s.liveness[id]; time.Since(l.lastSeen) > time.Second || l.activeConnections != 7 {
+		t.Errorf("liveness = %+v, want refreshed with 7 connections", *l)
+	}
+
+	if _, err := s.Heartbeat(context.Background(), &pb.HeartbeatRequest{ServiceId: "gone"}); !errors.Is(err, errors.CodeNotFound) {
+		t.Errorf("unknown id: err = %v, want NotFound so the caller re-registers", err)
+	}
+	if _, err := s.Heartbeat(context.Background(), &pb.HeartbeatRequest{}); !errors.Is(err, errors.CodeValidation) {
+		t.Errorf("empty id: err = %v, want a validation error", err)
+	}
+}
+
+func TestHeartbeatHealth(t *testing.T) {
+	s := newTestServer(t, config.RegistryConfig{})
+	id := register(t, s, "api", "10.0.0.1", 8080)
+	register(t, s, "api", "10.0.0.2", 8080)
+
+	discover := func(req *pb.DiscoverRequest) int {
+		t.Helper()
+		req.ServiceName = "api"
+		resp, err := s.Discover(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Discover: %v", err)
+		}
+		return len(resp.Services)
+	}
+	heartbeat := func(health pb.HealthStatus) {
+		t.Helper()
+		if _, err := s.Heartbeat(context.Background(), &pb.HeartbeatRequest{ServiceId: id, Health: health}); err != nil {
+			t.Fatalf("Heartbeat: %v", err)
+		}
+	}
+	health := func() pb.HealthStatus {
+		svc, _ := s.store.Get(id)
+		return svc.Health
+	}
+
+	heartbeat(pb.HealthStatus_HEALTH_STATUS_DRAINING)
+	if got := health(); got != pb.HealthStatus_HEALTH_STATUS_DRAINING {
+		t.Fatalf("health = %s, want DRAINING", got)
+	}
+
+	// a heartbeat without health keeps the last reported status
+	heartbeat(pb.HealthStatus_HEALTH_STATUS_UNKNOWN)
+	if got := health(); got != pb.HealthStatus_HEALTH_STATUS_DRAINING {
+		t.Errorf("health = %s after a plain heartbeat, want DRAINING", got)
+	}
+
+	// PickOne skips instances that are not routable; asking for a health lists them
+	resp, err := s.Discover(context.Background(), &pb.DiscoverRequest{ServiceName: "api", PickOne: true})
+	if err != nil {
+		t.Fatalf("Discover PickOne: %v", err)
+	}
+	if picked := resp.Services[0].Id; picked == id {
+		t.Errorf("picked draining instance %s", picked)
+	}
+	if n := discover(&pb.DiscoverRequest{Health: []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_DRAINING}}); n != 1 {
+		t.Errorf("%d draining services, want 1", n)
+	}
+
+	heartbeat(pb.HealthStatus_HEALTH_STATUS_HEALTHY)
+	if got := health(); got != pb.HealthStatus_HEALTH_STATUS_HEALTHY {
+		t.Errorf("health = %s, want HEALTHY", got)
+	}
+	if n := discover(&pb.DiscoverRequest{Health: []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_HEALTHY}}); n != 1 {
+		t.Errorf("%d healthy services, want 1", n)
+	}
+}
+
+func TestEvictExpired(t *testing.T) {
+	s := newTestServer(t, config.RegistryConfig{HeartbeatTimeout: 30 * time.Second})
+	stale := register(t, s, "api", "10.0.0.1", 8080)
+	fresh := register(t, s, "api", "10.0.0.2", 8080)
+
+	now := time.Now()
+	s.liveness[stale].lastSeen = now.Add(-31 * time.Second)
+	s.liveness[fresh].lastSeen = now.Add(-29 * time.Second)
+
+	evicted := s.evictExpired(now)
+	if len(evicted) != 1 || evicted[0].Id != stale {
+		t.Fatalf("evicted %v, want only %s", evicted, stale)
+	}
+	if _, err := s.store.Get(stale); !errors.Is(err, errors.CodeNotFound) {
+		t.Errorf("evicted service still stored: %v", err)
+	}
+	if _, ok := s.liveness[stale]; ok {
+		t.Error("liveness kept for an evicted service")
+	}
+	if _, err := s.store.Get(fresh); err != nil {
+		t.Errorf("live service evicted: %v", err)
+	}
+
+	if evicted := s.evictExpired(now); len(evicted) != 0 {
+		t.Errorf("second pass evicted %v", evicted)
+	}
+}
+
+func TestRunReaperStopsWithContext(t *testing.T) {
+	s := newTestServer(t, config.RegistryConfig{
+		HeartbeatInterval: 5 * time.Millisecond,
+		HeartbeatTimeout:  time.Millisecond,
+	})
+	id := register(t, s, "api", "10.0.0.1", 8080)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.RunReaper(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := s.store.Get(id); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("reaper did not evict the expired service")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reaper did not stop after cancel")
+	}
+}
+
+func TestDeregister(t *testing.T) {
+	s := newTestServer(t, config.RegistryConfig{})
+	id := register(t, s, "api", "10.0.0.1", 8080)
+
+	if _, err := s.Deregister(context.Background(), &pb.DeregisterRequest{ServiceId: id}); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	if _, err := s.store.Get(id); !errors.Is(err, errors.CodeNotFound) {
+		t.Errorf("service still stored: %v", err)
+	}
+	if _, ok := s.liveness[id]; ok {
+		t.Error("liveness kept for a deregistered service")
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		code errors.ErrorCode
+	}{
+		{"again", id, errors.CodeNotFound},
+		{"unknown", "nope", errors.CodeNotFound},
+		{"empty", "", errors.CodeValidation},
+	}
+	for _, tt := range tests {
+		_, err := s.Deregister(context.Background(), &pb.DeregisterRequest{ServiceId: tt.id})
+		if !errors.Is(err, tt.code) {
+			t.Errorf("%s: err = %v, want %s", tt.name, err, tt.code)
+		}
+	}
+}
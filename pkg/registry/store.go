@@ -0,0 +1,145 @@
+package registry
+
+import (
+	"context"
+	"sync"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+)
+
+// store types accepted by NewStore
+const (
+	StoreMemory = "memory"
+	StoreFile   = "file"
+)
+
+// events buffered per watcher before it is considered too slow and dropped
+const watchBufferSize = 64
+
+// StoreEventType describes a change to a stored service
+type StoreEventType int
+
+const (
+	EventAdded StoreEventType = iota
+	EventUpdated
+	EventDeleted
+)
+
+func (t StoreEventType) String() string {
+	switch t {
+	case EventAdded:
+		return "added"
+	case EventUpdated:
+		return "updated"
+	case EventDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// StoreEvent is a single change delivered to watchers.
+// For EventDeleted, Service is the last stored value.
+type StoreEvent struct {
+	Type    StoreEventType
+	Service *pb.Service
+}
+
+// Store persists registered services keyed by service ID.
+// Stored services must be treated as immutable; replace them with Put.
+type Store interface {
+	// Put creates or replaces a service
+	Put(svc *pb.Service) error
+
+	// Get returns a service or a NOT_FOUND error
+	Get(id string) (*pb.Service, error)
+
+	// List returns every stored service
+	List() ([]*pb.Service, error)
+
+	// Delete removes a service and returns it, or a NOT_FOUND error
+	Delete(id string) (*pb.Service, error)
+
+	// Watch returns the current services and a channel of subsequent changes.
+	// The channel is closed when ctx is done or the watcher falls too far behind.
+	Watch(ctx context.Context) ([]*pb.Service, <-chan StoreEvent, error)
+
+	Close() error
+}
+
+// NewStore creates the store selected in the registry configuration
+func NewStore(cfg config.StoreConfig) (Store, error) {
+	switch cfg.Type {
+	case "", StoreMemory:
+		return NewMemoryStore(), nil
+	case StoreFile:
+		return OpenFileStore(cfg)
+	default:
+		return nil, errors.Newf(errors.CodeConfigError, "unknown registry store type '%s'", cfg.Type)
+	}
+}
+
+// watchHub fans store events out to watchers without ever blocking writers
+type watchHub struct {
+	mu       sync.Mutex
+	watchers map[chan StoreEvent]struct{}
+}
+
+func newWatchHub() *watchHub {
+	return &watchHub{watchers: make(map[chan StoreEvent]struct{})}
+}
+
+// subscribe registers a watcher until ctx is done. Callers must hold the
+// store's write lock so that no event slips between snapshot and subscribe.
+func (h *watchHub) subscribe(ctx context.Context) <-chan StoreEvent {
+	ch := make(chan StoreEvent, watchBufferSize)
+
+	h.mu.Lock()
+	h.watchers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.remove(ch)
+	}()
+
+	return ch
+}
+
+// publish delivers an event to every watcher; a watcher whose buffer is full
+// is closed so it can resync instead of blocking the store
+func (h *watchHub) publish(event StoreEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.watchers {
+		select {
+		case ch <- event:
+		default:
+			delete(h.watchers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *watchHub) remove(ch chan StoreEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.watchers[ch]; ok {
+		delete(h.watchers, ch)
+		close(ch)
+	}
+}
+
+func (h *watchHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.watchers {
+		delete(h.watchers, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"strconv"
+	"strings"
+
+	"upm-simple/pkg/errors"
+)
+
+// Version is a parsed semantic version. Missing minor/patch parts are zero.
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          string
+}
+
+// ParseVersion parses versions like "1.2.3", "v1.2" or "2.0.0-beta.1".
+// Build metadata after "+" is ignored.
+func ParseVersion(s string) (Version, error) {
+	v, _, err := parseVersionParts(s)
+	return v, err
+}
+
+// Compare returns -1, 0 or 1. A prerelease sorts before its release.
+func (v Version) Compare(other Version) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	case v.Prerelease < other.Prerelease:
+		return -1
+	default:
+		return 1
+	}
+}
+
+func (v Version) String() string {
+	s := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// VersionConstraint is a set of comparisons that must all hold
+type VersionConstraint struct {
+	raw    string
+	checks []versionCheck
+}
+
+type versionCheck struct {
+	op      string
+	version Version
+}
+
+// ParseVersionConstraint parses a space or comma separated list of
+// comparisons, each one of:
+//
+//	1.2.3, =1.2.3     exact match
+//	>1.2 >=1.2 <2 <=2 !=1.3.0
+//	~1.2.3            >=1.2.3 <1.3.0
+//	^1.2.3            >=1.2.3 <2.0.0 (or <0.3.0 for 0.x)
+//	1.x, 1.2.*, *     wildcards
+//
+// An empty constraint matches every version.
+func ParseVersionConstraint(s string) (*VersionConstraint, error) {
+	c := &VersionConstraint{raw: s}
+
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+	for _, field := range fields {
+		checks, err := parseCheck(field)
+		if err != nil {
+			return nil, errors.Wrapf(err, errors.CodeInvalidArgument,
+				"invalid version constraint '%s'", s)
+		}
+		c.checks = append(c.checks, checks...)
+	}
+
+	return c, nil
+}
+
+// Match reports whether version satisfies the constraint.
+// Unparseable versions only match an empty constraint.
+func (c *VersionConstraint) Match(version string) bool {
+	if len(c.checks) == 0 {
+		return true
+	}
+
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false
+	}
+
+	for _, check := range c.checks {
+		if !check.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *VersionConstraint) String() string {
+	return c.raw
+}
+
+func (vc versionCheck) match(v Version) bool {
+	cmp := v.Compare(vc.version)
+	switch vc.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return false
+	}
+}
+
+func parseCheck(field string) ([]versionCheck, error) {
+	if field == "*" || field == "x" || field == "X" {
+		return nil, nil
+	}
+
+	for _, op := range []string{">=", "<=", "!=", ">", "<", "=", "~", "^"} {
+		if !strings.HasPrefix(field, op) {
+			continue
+		}
+
+		v, parts, err := parseVersionParts(strings.TrimPrefix(field, op))
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case "~":
+			// ~1 allows any 1.x, ~1.2 and ~1.2.3 allow patch updates only
+			upper := Version{Major: v.Major + 1}
+			if parts > 1 {
+				upper = Version{Major: v.Major, Minor: v.Minor + 1}
+			}
+			return rangeChecks(v, upper), nil
+		case "^":
+			// the first non-zero component must not change
+			upper := Version{Major: v.Major + 1}
+			if v.Major == 0 && parts > 1 {
+				upper = Version{Minor: v.Minor + 1}
+				if v.Minor == 0 && parts > 2 {
+					upper = Version{Patch: v.Patch + 1}
+				}
+			}
+			return rangeChecks(v, upper), nil
+		default:
+			return []versionCheck{{op: op, version: v}}, nil
+		}
+	}
+
+	// bare version, possibly with wildcards: 1, 1.x, 1.2.*
+	var nums []int
+	for _, part := range strings.Split(strings.TrimPrefix(field, "v"), ".") {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, errors.Newf(errors.CodeInvalidArgument, "invalid version '%s'", field)
+		}
+		nums = append(nums, n)
+	}
+
+	switch len(nums) {
+	case 0:
+		return nil, nil
+	case 1:
+		return rangeChecks(Version{Major: nums[0]}, Version{Major: nums[0] + 1}), nil
+	case 2:
+		return rangeChecks(Version{Major: nums[0], Minor: nums[1]},
+			Version{Major: nums[0], Minor: nums[1] + 1}), nil
+	default:
+		v, err := ParseVersion(field)
+		if err != nil {
+			return nil, err
+		}
+		return []versionCheck{{op: "=", version: v}}, nil
+	}
+}
+
+// rangeChecks builds lower <= v < upper, where the upper bound excludes
+// prereleases of the next version
+func rangeChecks(lower, upper Version) []versionCheck {
+	upper.Prerelease = "0"
+	return []versionCheck{
+		{op: ">=", version: lower},
+		{op: "<", version: upper},
+	}
+}
+
+// parseVersionParts parses a version and reports how many numeric
+// components were given
+func parseVersionParts(s string) (Version, int, error) {
+	var v Version
+	raw := s
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.Prerelease = s[i+1:]
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if s == "" || len(parts) > 3 {
+		return Version{}, 0, errors.Newf(errors.CodeInvalidArgument, "invalid version '%s'", raw)
+	}
+
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, 0, errors.Newf(errors.CodeInvalidArgument, "invalid version '%s'", raw)
+		}
+		*nums[i] = n
+	}
+
+	return v, len(parts), nil
+}
@@ -0,0 +1,151 @@
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "upm-simple/internal"
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+
+	"google.golang.org/grpc"
+)
+
+// watchStream records the events sent by Watch
+type watchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *pb.WatchEvent
+}
+
+func newWatchStream(ctx context.Context) *watchStream {
+	return &watchStream{ctx: ctx, events: make(chan *pb.WatchEvent, 64)}
+}
+
+func (w *watchStream) Context() context.Context { return w.ctx }
+
+func (w *watchStream) Send(event *pb.WatchEvent) error {
+	w.events <- event
+	return nil
+}
+
+func (w *watchStream) next(t *testing.T) *pb.WatchEvent {
+	t.Helper()
+
+	select {
+	case event := <-w.events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no watch event within 1s")
+		return nil
+	}
+}
+
+func eventString(event *pb.WatchEvent) string {
+	if event.Service == nil {
+		return event.Type.String()
+	}
+	return event.Type.String() + " " + event.Service.Id
+}
+
+// startWatch runs Watch in the background and returns its stream and result
+func startWatch(s *Server, req *pb.WatchRequest) (*watchStream, context.CancelFunc, <-chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := newWatchStream(ctx)
+	result := make(chan error, 1)
+	go func() { result <- s.Watch(req, stream) }()
+	return stream, cancel, result
+}
+
+func TestWatchOrdering(t *testing.T) {
+	s := newTestServer(t, config.RegistryConfig{})
+	a := register(t, s, "api", "10.0.0.1", 8080)
+	register(t, s, "db", "10.0.0.9", 5432)
+
+	stream, cancel, result := startWatch(s, &pb.WatchRequest{ServiceName: "api"})
+	defer cancel()
+
+	// the snapshot of matching services comes first, then SYNCED
+	if got := eventString(stream.next(t)); got != "ADDED "+a {
+		t.Fatalf("first event = %s, want ADDED %s", got, a)
+	}
+	if got := eventString(stream.next(t)); got != "SYNCED" {
+		t.Fatalf("second event = %s, want SYNCED", got)
+	}
+
+	b := register(t, s, "api", "10.0.0.2", 8080)
+	register(t, s, "db", "10.0.0.8", 5432) // filtered out
+	if _, err := s.Heartbeat(context.Background(), &pb.HeartbeatRequest{
+		ServiceId: b,
+		Health:    pb.HealthStatus_HEALTH_STATUS_DRAINING,
+	}); err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if _, err := s.Deregister(context.Background(), &pb.DeregisterRequest{ServiceId: a}); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+
+	want := []string{"ADDED " + b, "UPDATED " + b, "REMOVED " + a}
+	for _, w := range want {
+		if got := eventString(stream.next(t)); got != w {
+			t.Fatalf("event = %s, want %s", got, w)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-result:
+		if err != context.Canceled {
+			t.Errorf("Watch returned %v after the client left, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Watch did not return after the client left")
+	}
+}
+
+func TestWatchEndsOnShutdown(t *testing.T) {
+	s := newTestServer(t, config.RegistryConfig{})
+
+	stream, cancel, result := startWatch(s, &pb.WatchRequest{})
+	defer cancel()
+	if got := eventString(stream.next(t)); got != "SYNCED" {
+		t.Fatalf("first event = %s, want SYNCED", got)
+	}
+
+	s.Shutdown()
+	s.Shutdown() // safe to call twice
+
+	select {
+	case err := <-result:
+		if !errors.Is(err, errors.CodeServiceUnavailable) {
+			t.Errorf("Watch returned %v, want ServiceUnavailable", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Watch still running after Shutdown")
+	}
+}
+
+func TestWatchSlowWatcherResyncs(t *testing.T) {
+	s := newTestServer(t, config.RegistryConfig{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, events, err := s.store.Watch(ctx)
+	if err != nil {
+		t.Fatalf("store Watch: %v", err)
+	}
+
+	// nobody reads, so the buffer fills and the watcher is dropped
+	for i := 0; i <= watchBufferSize; i++ {
+		register(t, s, "api", "10.0.0.1", int32(1000+i))
+	}
+
+	n := 0
+	for range events {
+		n++
+	}
+	if n != watchBufferSize {
+		t.Errorf("received %d events before the close, want the buffer size %d", n, watchBufferSize)
+	}
+}
@@ -0,0 +1,196 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"upm-simple/pkg/errors"
+	"upm-simple/pkg/logger"
+)
+
+// how often the files are checked for changes during handshakes
+const defaultCheckInterval = 5 * time.Second
+
+// KeyPairReloader serves a certificate and key from disk and picks up new
+// files when they are rotated (modification time changes). If a rotated
+// pair fails to load, the previous one keeps being served.
+type KeyPairReloader struct {
+	certPath      string
+	keyPath       string
+	checkInterval time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	lastCheck time.Time
+}
+
+// NewKeyPairReloader loads the key pair once and fails if it is invalid
+func NewKeyPairReloader(certPath, keyPath string) (*KeyPairReloader, error) {
+	r := &KeyPairReloader{
+		certPath:      certPath,
+		keyPath:       keyPath,
+		checkInterval: defaultCheckInterval,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate can be used as tls.Config.GetCertificate
+func (r *KeyPairReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+// GetClientCertificate can be used as tls.Config.GetClientCertificate
+func (r *KeyPairReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+func (r *KeyPairReloader) current() *tls.Certificate {
+	r.mu.RLock()
+	due := time.Since(r.lastCheck) >= r.checkInterval
+	cert := r.cert
+	r.mu.RUnlock()
+
+	if due && r.changed() {
+		if err := r.reload(); err != nil {
+			logger.Default().Warn("keeping previous TLS certificate",
+				logger.FieldString("cert", r.certPath),
+				logger.FieldError(err),
+			)
+		} else {
+			logger.Default().Info("TLS certificate reloaded", logger.FieldString("cert", r.certPath))
+		}
+
+		r.mu.RLock()
+		cert = r.cert
+		r.mu.RUnlock()
+	}
+
+	return cert
+}
+
+func (r *KeyPairReloader) changed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastCheck = time.Now()
+	return modTime(r.certPath) != r.certMod || modTime(r.keyPath) != r.keyMod
+}
+
+func (r *KeyPairReloader) reload() error {
+	certMod, keyMod := modTime(r.certPath), modTime(r.keyPath)
+
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return errors.Wrapf(err, errors.CodeConfigError,
+			"cannot load TLS key pair %s / %s", r.certPath, r.keyPath)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.certMod = certMod
+	r.keyMod = keyMod
+	r.lastCheck = time.Now()
+	r.mu.Unlock()
+
+	return nil
+}
+
+// CAReloader serves a CA certificate pool from a PEM bundle on disk and
+// reloads it when the file changes
+type CAReloader struct {
+	path          string
+	checkInterval time.Duration
+
+	mu        sync.RWMutex
+	pool      *x509.CertPool
+	mod       time.Time
+	lastCheck time.Time
+}
+
+// NewCAReloader loads the bundle once and fails if it holds no certificates
+func NewCAReloader(path string) (*CAReloader, error) {
+	r := &CAReloader{
+		path:          path,
+		checkInterval: defaultCheckInterval,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Pool returns the current CA pool, reloading it first if the file changed
+func (r *CAReloader) Pool() *x509.CertPool {
+	r.mu.Lock()
+	due := time.Since(r.lastCheck) >= r.checkInterval
+	changed := false
+	if due {
+		r.lastCheck = time.Now()
+		changed = modTime(r.path) != r.mod
+	}
+	r.mu.Unlock()
+
+	if changed {
+		if err := r.reload(); err != nil {
+			logger.Default().Warn("keeping previous CA bundle",
+				logger.FieldString("ca", r.path),
+				logger.FieldError(err),
+			)
+		} else {
+			logger.Default().Info("CA bundle reloaded", logger.FieldString("ca", r.path))
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pool
+}
+
+func (r *CAReloader) reload() error {
+	mod := modTime(r.path)
+
+	pool, err := LoadCAPool(r.path)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.pool = pool
+	r.mod = mod
+	r.lastCheck = time.Now()
+	r.mu.Unlock()
+
+	return nil
+}
+
+// LoadCAPool reads a PEM bundle and fails if it holds no certificates
+func LoadCAPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, errors.CodeConfigError, "cannot read CA bundle %s", path)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Newf(errors.CodeConfigError, "no certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// modTime returns the file's modification time, following symlinks so that
+// atomically swapped links (as with Kubernetes secrets) count as a change
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,119 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// ClientOptions configures TLS for outgoing connections
+type ClientOptions struct {
+	// CA bundle used to verify the server; empty uses the system roots
+	CAPath string
+
+	// client certificate for mutual TLS; both or neither must be set
+	CertPath string
+	KeyPath  string
+
+	// overrides the name checked against the server certificate
+	ServerName string
+}
+
+// ServerConfig builds a server TLS config from the server section.
+// Certificates and the client CA bundle are reloaded when they change on
+// disk. With TLSCAPath set, client certificates are verified if presented;
+// RequireClientCert makes them mandatory (mutual TLS).
+func ServerConfig(cfg config.ServerConfig) (*tls.Config, error) {
+	if cfg.TLSCertPath == "" || cfg.TLSKeyPath == "" {
+		return nil, errors.New(errors.CodeConfigError, "TLS requires tls_cert_path and tls_key_path")
+	}
+	if cfg.RequireClientCert && cfg.TLSCAPath == "" {
+		return nil, errors.New(errors.CodeConfigError, "require_client_cert requires tls_ca_path")
+	}
+
+	keyPair, err := NewKeyPairReloader(cfg.TLSCertPath, cfg.TLSKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: keyPair.GetCertificate,
+	}
+
+	if cfg.TLSCAPath == "" {
+		return tlsConfig, nil
+	}
+
+	clientCAs, err := NewCAReloader(cfg.TLSCAPath)
+	if err != nil {
+		return nil, err
+	}
+
+	clientAuth := tls.VerifyClientCertIfGiven
+	if cfg.RequireClientCert {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	// resolve the CA pool per handshake so a rotated bundle takes effect
+	tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := tlsConfig.Clone()
+		c.GetConfigForClient = nil
+		c.ClientAuth = clientAuth
+		c.ClientCAs = clientCAs.Pool()
+		return c, nil
+	}
+
+	return tlsConfig, nil
+}
+
+// ClientConfig builds a client TLS config. The client certificate, if any,
+// is reloaded when it changes on disk.
+func ClientConfig(opts ClientOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: opts.ServerName,
+	}
+
+	if opts.CAPath != "" {
+		roots, err := LoadCAPool(opts.CAPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	if (opts.CertPath == "") != (opts.KeyPath == "") {
+		return nil, errors.New(errors.CodeConfigError, "client certificate and key must be set together")
+	}
+	if opts.CertPath != "" {
+		keyPair, err := NewKeyPairReloader(opts.CertPath, opts.KeyPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = keyPair.GetClientCertificate
+	}
+
+	return tlsConfig, nil
+}
+
+// ServerCredentials wraps ServerConfig as gRPC transport credentials
+func ServerCredentials(cfg config.ServerConfig) (credentials.TransportCredentials, error) {
+	tlsConfig, err := ServerConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// ClientCredentials wraps ClientConfig as gRPC transport credentials
+func ClientCredentials(opts ClientOptions) (credentials.TransportCredentials, error) {
+	tlsConfig, err := ClientConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
@@ -0,0 +1,341 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"upm-simple/pkg/config"
+	"upm-simple/pkg/errors"
+)
+
+// testCert is a certificate and key issued for a test
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+var serial int64
+
+// issue creates a certificate for cn signed by parent, or self-signed as a CA
+// when parent is nil
+func issue(t *testing.T, cn string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	serial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{cn},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// writePEM writes the certificate (and key, if keyPath is set) and moves the
+// modification time forward so a reloader sees the change
+func writePEM(t *testing.T, c *testCert, certPath, keyPath string, mod time.Time) {
+	t.Helper()
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der})
+	writeFile(t, certPath, certPEM, mod)
+
+	if keyPath != "" {
+		der, err := x509.MarshalECPrivateKey(c.key)
+		if err != nil {
+			t.Fatalf("marshal key: %v", err)
+		}
+		writeFile(t, keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), mod)
+	}
+}
+
+func writeFile(t *testing.T, path string, content []byte, mod time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatalf("chtimes %s: %v", path, err)
+	}
+}
+
+func TestKeyPairReloader(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca := issue(t, "ca", nil)
+	now := time.Now()
+
+	first := issue(t, "first", ca)
+	writePEM(t, first, certPath, keyPath, now.Add(-time.Minute))
+
+	r, err := NewKeyPairReloader(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("NewKeyPairReloader: %v", err)
+	}
+	r.checkInterval = 0
+
+	serialOf := func() int64 {
+		t.Helper()
+		cert, err := r.GetCertificate(nil)
+		if err != nil {
+			t.Fatalf("GetCertificate: %v", err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatalf("parse served certificate: %v", err)
+		}
+		return leaf.SerialNumber.Int64()
+	}
+
+	if got := serialOf(); got != first.cert.SerialNumber.Int64() {
+		t.Fatalf("serving serial %d, want the first certificate", got)
+	}
+
+	second := issue(t, "second", ca)
+	writePEM(t, second, certPath, keyPath, now)
+	if got := serialOf(); got != second.cert.SerialNumber.Int64() {
+		t.Fatalf("serving serial %d after rotation, want the second certificate", got)
+	}
+
+	// a half-written rotation (new cert, old key) keeps the working pair
+	writePEM(t, issue(t, "third", ca), certPath, "", now.Add(time.Minute))
+	if got := serialOf(); got != second.cert.SerialNumber.Int64() {
+		t.Errorf("serving serial %d after a bad rotation, want the second certificate", got)
+	}
+}
+
+func TestNewKeyPairReloaderInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewKeyPairReloader(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key")); !errors.Is(err, errors.CodeConfigError) {
+		t.Errorf("err = %v, want ConfigError", err)
+	}
+}
+
+func TestCAReloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ca.crt")
+	first, second := issue(t, "first-ca", nil), issue(t, "second-ca", nil)
+	leaf := issue(t, "leaf", second)
+	now := time.Now()
+
+	writePEM(t, first, path, "", now.Add(-time.Minute))
+	r, err := NewCAReloader(path)
+	if err != nil {
+		t.Fatalf("NewCAReloader: %v", err)
+	}
+	r.checkInterval = 0
+
+	verifies := func() bool {
+		_, err := leaf.cert.Verify(x509.VerifyOptions{
+			Roots:     r.Pool(),
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		return err == nil
+	}
+
+	if verifies() {
+		t.Fatal("leaf of the second CA verified against the first bundle")
+	}
+
+	writePEM(t, second, path, "", now)
+	if !verifies() {
+		t.Fatal("rotated bundle not picked up")
+	}
+
+	writeFile(t, path, []byte("not a certificate"), now.Add(time.Minute))
+	if !verifies() {
+		t.Error("a broken bundle replaced the working one")
+	}
+
+	if _, err := LoadCAPool(path); !errors.Is(err, errors.CodeConfigError) {
+		t.Errorf("LoadCAPool err = %v, want ConfigError", err)
+	}
+}
+
+func TestServerConfigValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ServerConfig
+	}{
+		{"missing key", config.ServerConfig{TLSCertPath: "tls.crt"}},
+		{"client cert without CA", config.ServerConfig{TLSCertPath: "tls.crt", TLSKeyPath: "tls.key", RequireClientCert: true}},
+	}
+	for _, tt := range tests {
+		if _, err := ServerConfig(tt.cfg); !errors.Is(err, errors.CodeConfigError) {
+			t.Errorf("%s: err = %v, want ConfigError", tt.name, err)
+		}
+	}
+
+	if _, err := ClientConfig(ClientOptions{CertPath: "tls.crt"}); !errors.Is(err, errors.CodeConfigError) {
+		t.Errorf("client cert without key: err = %v, want ConfigError", err)
+	}
+}
+
+// pki writes a CA, a server pair and a client pair into a temp dir
+type pki struct {
+	caPath, serverCert, serverKey, clientCert, clientKey string
+}
+
+func newPKI(t *testing.T) pki {
+	t.Helper()
+
+	dir := t.TempDir()
+	p := pki{
+		caPath:     filepath.Join(dir, "ca.crt"),
+		serverCert: filepath.Join(dir, "server.crt"),
+		serverKey:  filepath.Join(dir, "server.key"),
+		clientCert: filepath.Join(dir, "client.crt"),
+		clientKey:  filepath.Join(dir, "client.key"),
+	}
+	ca := issue(t, "ca", nil)
+	now := time.Now()
+	writePEM(t, ca, p.caPath, "", now)
+	writePEM(t, issue(t, "localhost", ca), p.serverCert, p.serverKey, now)
+	writePEM(t, issue(t, "client", ca), p.clientCert, p.clientKey, now)
+	return p
+}
+
+// handshake serves one TLS connection and returns the client and server errors
+func handshake(t *testing.T, server, client *tls.Config) (error, error) {
+	t.Helper()
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer lis.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		if err := conn.(*tls.Conn).Handshake(); err != nil {
+			serverErr <- err
+			return
+		}
+		_, err = conn.Write([]byte{1})
+		serverErr <- err
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+	if err == nil {
+		// TLS 1.3 reports a rejected client certificate on the first read
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+	return err, <-serverErr
+}
+
+func TestServerConfigClientAuth(t *testing.T) {
+	p := newPKI(t)
+	withCert := ClientOptions{CAPath: p.caPath, CertPath: p.clientCert, KeyPath: p.clientKey, ServerName: "localhost"}
+	withoutCert := ClientOptions{CAPath: p.caPath, ServerName: "localhost"}
+
+	tests := []struct {
+		name    string
+		require bool
+		client  ClientOptions
+		wantErr bool
+	}{
+		{"optional, with cert", false, withCert, false},
+		{"optional, without cert", false, withoutCert, false},
+		{"required, with cert", true, withCert, false},
+		{"required, without cert", true, withoutCert, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, err := ServerConfig(config.ServerConfig{
+				TLSCertPath:       p.serverCert,
+				TLSKeyPath:        p.serverKey,
+				TLSCAPath:         p.caPath,
+				RequireClientCert: tt.require,
+			})
+			if err != nil {
+				t.Fatalf("ServerConfig: %v", err)
+			}
+
+			perConn, err := server.GetConfigForClient(&tls.ClientHelloInfo{})
+			if err != nil {
+				t.Fatalf("GetConfigForClient: %v", err)
+			}
+			want := tls.VerifyClientCertIfGiven
+			if tt.require {
+				want = tls.RequireAndVerifyClientCert
+			}
+			if perConn.ClientAuth != want || perConn.ClientCAs == nil || perConn.GetConfigForClient != nil {
+				t.Errorf("per-connection config = %v auth, CAs %v; want %v with the CA pool", perConn.ClientAuth, perConn.ClientCAs != nil, want)
+			}
+
+			client, err := ClientConfig(tt.client)
+			if err != nil {
+				t.Fatalf("ClientConfig: %v", err)
+			}
+			clientErr, serverErr := handshake(t, server, client)
+			if failed := clientErr != nil || serverErr != nil; failed != tt.wantErr {
+				t.Errorf("handshake errors = %v / %v, want failure %v", clientErr, serverErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServerConfigWithoutCA(t *testing.T) {
+	p := newPKI(t)
+
+	server, err := ServerConfig(config.ServerConfig{TLSCertPath: p.serverCert, TLSKeyPath: p.serverKey})
+	if err != nil {
+		t.Fatalf("ServerConfig: %v", err)
+	}
+	if server.GetConfigForClient != nil {
+		t.Error("client certificates checked without a CA bundle")
+	}
+
+	client, err := ClientConfig(ClientOptions{CAPath: p.caPath, ServerName: "localhost"})
+	if err != nil {
+		t.Fatalf("ClientConfig: %v", err)
+	}
+	if clientErr, serverErr := handshake(t, server, client); clientErr != nil || serverErr != nil {
+		t.Errorf("handshake errors = %v / %v", clientErr, serverErr)
+	}
+}
@@ -9,27 +9,18 @@ if ($LASTEXITCODE -eq 0) {
     Write-Host "   NATS not found, using local test" -ForegroundColor Yellow
 }
 
-#  build server
-Write-Host "2. Building server..." -ForegroundColor Yellow
-go build -o server.exe ./cmd/server.go
+# build CLI
+Write-Host "2. Building upm CLI..." -ForegroundColor Yellow
+go build -o upm.exe ./cmd/upm
 if ($LASTEXITCODE -eq 0) {
-    Write-Host "   Server build: PASS" -ForegroundColor Green
+    Write-Host "   CLI build: PASS" -ForegroundColor Green
 } else {
-    Write-Host "   Server build: FAIL" -ForegroundColor Red
+    Write-Host "   CLI build: FAIL" -ForegroundColor Red
     exit 1
 }
 
-# build client
-Write-Host "3. Building client..." -ForegroundColor Yellow
-go build -o client.exe ./cmd/client.go
-if ($LASTEXITCODE -eq 0) {
-    Write-Host "   Client build: PASS" -ForegroundColor Green
-} else {
-    Write-Host "   Client build: FAIL" -ForegroundColor Red
-}
-
 # check generated files
-Write-Host "4. Checking generated files..." -ForegroundColor Yellow
+Write-Host "3. Checking generated files..." -ForegroundColor Yellow
 if (Test-Path "internal\service_grpc.pb.go") {
     Write-Host "   Protobuf files: EXISTS" -ForegroundColor Green
 } else {
@@ -38,11 +29,11 @@ if (Test-Path "internal\service_grpc.pb.go") {
 
 Write-Host "`n=== TEST INSTRUCTIONS ===" -ForegroundColor Cyan
 Write-Host "`nTo run server (Terminal 1):" -ForegroundColor White
-Write-Host "  go run cmd\server.go" -ForegroundColor Green
+Write-Host "  .\upm.exe registry serve --env dev" -ForegroundColor Green
 Write-Host "`nTo test (Terminal 2):" -ForegroundColor White
-Write-Host "  go run cmd\client.go" -ForegroundColor Green
+Write-Host "  .\upm.exe registry register --name mock-engine --port 8080 --version 1.0.0 --tag http" -ForegroundColor Green
+Write-Host "  .\upm.exe registry list --name mock-engine" -ForegroundColor Green
 Write-Host "`nExpected output:" -ForegroundColor White
-Write-Host "  Registered with ID: mock-engine-localhost-8080" -ForegroundColor Gray
-Write-Host "  Found 1 services" -ForegroundColor Gray
-Write-Host "  Test PASSED!" -ForegroundColor Gray
+Write-Host "  mock-engine-localhost-8080" -ForegroundColor Gray
+Write-Host "  mock-engine-localhost-8080  mock-engine  localhost:8080  1.0.0  ..." -ForegroundColor Gray
 Write-Host "`nIf you see this, Week 3 is COMPLETE!" -ForegroundColor Green